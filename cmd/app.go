@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/example/harvestcli/internal/config"
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+// commonOptions are the flags shared by every command.
+type commonOptions struct {
+	configPath   string
+	ignoreConfig bool
+	profile      string
+	timeout      time.Duration
+}
+
+func addCommonFlags(fs *flag.FlagSet, o *commonOptions) {
+	fs.StringVar(&o.configPath, "c", "", "Config file path (default: the nearest "+config.LocalConfigName+" up to the git root or home directory)")
+	fs.BoolVar(&o.ignoreConfig, "i", false, "Ignore loading local configuration")
+	fs.StringVar(&o.profile, "profile", "", "Global config profile to use (default: $HARVEST_PROFILE, the directory's pin, or default_profile)")
+	fs.DurationVar(&o.timeout, "timeout", harvest.DefaultTimeout, "Timeout for each Harvest API request (0 disables)")
+}
+
+// app holds the configuration and API client shared by command handlers.
+type app struct {
+	ctx      context.Context
+	logger   *log.Logger
+	opts     commonOptions
+	resolved *config.Resolved
+	profile  *config.Profile
+	cfg      *config.Config
+	client   harvest.API
+	userID   int64
+}
+
+// clientOptions builds the harvest.Client options for a profile.
+func clientOptions(profile *config.Profile, timeout time.Duration) []harvest.Option {
+	opts := []harvest.Option{harvest.WithTimeout(timeout)}
+	if baseURL := profile.HarvestBaseURL; baseURL != "" {
+		opts = append(opts, harvest.WithBaseURL(baseURL))
+	}
+	return opts
+}
+
+// resolveConfig layers the command-line flags and environment over the local
+// and global configuration.
+func resolveConfig(ctx context.Context, logger *log.Logger, opts commonOptions) *config.Resolved {
+	resolved, err := config.Resolve(ctx, config.Flags{
+		Profile:     opts.profile,
+		LocalPath:   opts.configPath,
+		IgnoreLocal: opts.ignoreConfig,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		logger.Fatalf("Failed to load config: %v", err)
+		os.Exit(1)
+	}
+	return resolved
+}
+
+// newApp resolves the configuration, running first-time setup for the
+// selected profile if no credentials were found, and creates the API client.
+func newApp(ctx context.Context, logger *log.Logger, opts commonOptions) *app {
+	resolved := resolveConfig(ctx, logger, opts)
+	if resolved.LocalFound && !opts.ignoreConfig {
+		logger.Printf("using local config %s", resolved.LocalPath)
+		// Say which file was picked up, since it may be in a parent
+		// directory, but only on a terminal so status bar output stays clean.
+		if opts.configPath == "" && stderrIsTerminal() {
+			fmt.Fprintf(os.Stderr, "Using %s\n", resolved.LocalPath)
+		}
+	}
+
+	// Check if the credentials are complete, if not, prompt for setup
+	if !resolved.Profile.Complete() {
+		if resolved.Stored() == nil && len(resolved.Global.Profiles) > 0 {
+			fmt.Fprintln(os.Stderr, resolved.Global.UnknownProfileError(resolved.ProfileName))
+			os.Exit(1)
+		}
+		setupErr := setupGlobalConfig(ctx, resolved.Global, resolved.ProfileName, "auto", clientOptions(resolved.Profile, opts.timeout))
+		if setupErr != nil {
+			logger.Fatalf("Failed to setup global config: %v", setupErr)
+			os.Exit(1)
+		}
+		resolved = resolveConfig(ctx, logger, opts)
+	}
+
+	profile := resolved.Profile
+	client, clientErr := harvest.NewClient(profile.HarvestAccountID, profile.HarvestAccessToken, clientOptions(profile, opts.timeout)...)
+	if clientErr != nil {
+		logger.Fatalf("Auth error: %v", clientErr)
+		os.Exit(1)
+	}
+
+	return &app{
+		ctx:      ctx,
+		logger:   logger,
+		opts:     opts,
+		resolved: resolved,
+		profile:  profile,
+		cfg:      resolved.Local,
+		client:   client,
+	}
+}
+
+// currentUserID resolves the current user's ID on first use.
+func (a *app) currentUserID() int64 {
+	if a.userID == 0 {
+		a.userID = resolveUserID(a.ctx, a.client, a.resolved, a.logger)
+	}
+	return a.userID
+}
+
+// saveLocalConfig writes the directory config unless it is being ignored.
+// A directory set up with a profile other than the default is pinned to it.
+func (a *app) saveLocalConfig() {
+	if a.opts.ignoreConfig {
+		return
+	}
+	if name := a.resolved.ProfileName; a.cfg.Profile == "" && name != a.resolved.Global.DefaultProfile {
+		a.cfg.Profile = name
+	}
+	if err := a.cfg.Save(a.resolved.LocalPath); err != nil {
+		a.logger.Printf("Failed to save config: %v", err)
+		return
+	}
+	if !a.resolved.LocalFound {
+		a.resolved.LocalFound = true
+		fmt.Printf("Saved defaults to %s\n", a.resolved.LocalPath)
+	}
+}
+
+// stderrIsTerminal reports whether stderr is a terminal rather than a pipe or
+// file, so notes written there won't end up in a script's output.
+func stderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
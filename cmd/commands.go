@@ -0,0 +1,666 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/harvestcli/internal/config"
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/idle"
+	"github.com/example/harvestcli/internal/timeparse"
+)
+
+// command is a harvest_cli subcommand. run parses its own flags from args.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, logger *log.Logger, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "start", summary: "Start a timer for this directory's project and task (default)", run: runStart},
+		{name: "stop", summary: "Stop the running timer", run: runStop},
+		{name: "switch", summary: "Stop the running timer and start another, reporting both", run: runSwitch},
+		{name: "restart", summary: "Pick one of today's entries and restart it", run: runRestart},
+		{name: "edit", summary: "Pick an entry and change its project, task, notes, date or time", run: runEdit},
+		{name: "delete", summary: "Pick entries and delete them after confirming", run: runDelete},
+		{name: "status", summary: "Print the running timer for tmux, SketchyBar or Waybar", run: runStatus},
+		{name: "toggle", summary: "Stop the running timer, or resume today's latest entry", run: runToggle},
+		{name: "add", summary: "Add, subtract or set time on the running timer or any entry", run: runAdd},
+		{name: "entries", summary: "List time entries", run: runEntries},
+		{name: "watch", summary: "Watch for idle time and offer to discard or split it", run: runWatch},
+		{name: "week", summary: "Show a week's timesheet by project, task and day", run: runWeek},
+		{name: "expenses", summary: "List or create expenses (list|create)", run: runExpenses},
+		{name: "invoices", summary: "List invoices (list)", run: runInvoices},
+		{name: "config", summary: "Manage global configuration (setup)", run: runConfig},
+		{name: "help", summary: "Show help for a command", run: runHelp},
+	}
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func printUsage() {
+	w := os.Stderr
+	fmt.Fprintln(w, "Usage: harvest_cli <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'harvest_cli help <command>' for a command's flags.")
+	fmt.Fprintln(w, "Without a command, harvest_cli starts a timer.")
+}
+
+// newFlagSet creates the flag set for a command with help text built from
+// its synopsis and description.
+func newFlagSet(name, synopsis, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "Usage: harvest_cli %s %s\n\n%s\n", name, synopsis, description)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(w, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// usageError prints msg and the command's usage, then exits.
+func usageError(fs *flag.FlagSet, format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n\n", args...)
+	fs.Usage()
+	os.Exit(2)
+}
+
+// dateRangeFlags registers --from and --to.
+func dateRangeFlags(fs *flag.FlagSet) (from, to *string) {
+	from = fs.String("from", "", "From date (YYYY-MM-DD)")
+	to = fs.String("to", "", "To date (YYYY-MM-DD)")
+	return from, to
+}
+
+// optional returns nil for an empty string so it can be passed as an
+// omitted filter.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// idOrName splits a filter value into a numeric ID for the API or a name to
+// match locally.
+func idOrName(v string) (*int64, string) {
+	if v == "" {
+		return nil, ""
+	}
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return &id, ""
+	}
+	return nil, v
+}
+
+// optionalBool is a boolean flag that stays nil unless given, so --billable
+// and --billable=false can both be told apart from no filter.
+type optionalBool struct {
+	value *bool
+}
+
+func (b *optionalBool) String() string {
+	if b == nil || b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b.value)
+}
+
+func (b *optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.value = &v
+	return nil
+}
+
+func (b *optionalBool) IsBoolFlag() bool { return true }
+
+// subcommand splits a leading action such as "list" off args, returning def
+// when args start with a flag or are empty.
+func subcommand(args []string, def string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return def, args
+	}
+	return args[0], args[1:]
+}
+
+// parseMinutes parses an amount for the add command: a whole number of
+// minutes, or a duration with units such as 1h30m or 1:30. Bare decimals are
+// refused because "1.5" reads as hours as easily as minutes. The result is in
+// decimal hours.
+func parseMinutes(s string) (float64, error) {
+	v := strings.TrimSpace(s)
+	if minutes, err := strconv.Atoi(v); err == nil {
+		if minutes > 24*60 || minutes < -24*60 {
+			return 0, fmt.Errorf("invalid amount %q: more than 24 hours", s)
+		}
+		return float64(minutes) / 60, nil
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil && strings.Trim(v, "+-.0123456789") == "" {
+		return 0, fmt.Errorf("%q has no unit: give whole minutes, or hours with a unit such as 1h30m or 1:30", s)
+	}
+	if hours, err := timeparse.ParseHours(v); err == nil {
+		return hours, nil
+	}
+	return 0, fmt.Errorf("invalid amount %q: give whole minutes or a duration such as 1h30m or 1:30", s)
+}
+
+func runStart(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("start", "[flags]",
+		"Creates a time entry for this directory's project and task, prompting for\nany that aren't configured yet, and saves them as the directory's defaults.\nToday's entries start a running timer; any other --date creates a stopped\nentry you can add time to, and --duration creates a completed entry.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	var opts startOptions
+	fs.StringVar(&opts.note, "n", "", "Initial notes text")
+	fs.StringVar(&opts.ticket, "t", "", "External ticket number to prefix notes")
+	fs.BoolVar(&opts.lazy, "l", false, "Lazy project selection (hide list until typing)")
+	date := fs.String("date", "", "Day to log against: YYYY-MM-DD, yesterday, mon..sun or -2d (default: today)")
+	duration := fs.String("duration", "", "Log a completed entry: 1h30m, 90m, 1.5 or a range like 09:00-10:30")
+	fs.Parse(args)
+
+	if *duration != "" {
+		span, err := timeparse.ParseSpan(*duration)
+		if err != nil {
+			usageError(fs, "%v", err)
+		}
+		opts.span = &span
+	}
+
+	if *date != "" {
+		d, err := timeparse.ParseDate(*date, time.Now())
+		if err != nil {
+			usageError(fs, "%v", err)
+		}
+		opts.date = d
+	}
+
+	return handleStart(newApp(ctx, logger, common), os.Stdout, opts)
+}
+
+func runStop(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("stop", "[flags]", "Stops the currently running timer.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	fs.Parse(args)
+
+	a := newApp(ctx, logger, common)
+	return handleStopTimer(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger)
+}
+
+func runSwitch(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("switch", "[flags]",
+		"Stops the running timer and reports its final duration, then starts the\nchosen project, task and notes. A stopped entry from today with the same\nproject, task and notes is resumed instead of creating a new one.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	var opts switchOptions
+	fs.StringVar(&opts.project, "project", "", "Project ID or name (prompted for if omitted)")
+	fs.StringVar(&opts.task, "task", "", "Task ID or name (prompted for if omitted)")
+	fs.StringVar(&opts.note, "n", "", "Notes text")
+	fs.StringVar(&opts.ticket, "t", "", "External ticket number to prefix notes")
+	fs.BoolVar(&opts.lazy, "l", false, "Lazy project selection (hide list until typing)")
+	fs.Parse(args)
+
+	handleSwitch(newApp(ctx, logger, common), opts)
+	return nil
+}
+
+func runRestart(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("restart", "[flags]", "Lists today's time entries and restarts the one you pick.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	fs.Parse(args)
+
+	a := newApp(ctx, logger, common)
+	return handleTimeEntrySelection(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger)
+}
+
+func runEdit(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("edit", "[flags]",
+		"Lists the day's time entries, then opens a form for the one you pick.\nOnly the fields you change are sent to Harvest.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	date := fs.String("date", "", "Day to list entries for: YYYY-MM-DD, yesterday, mon..sun or -2d (default: today)")
+	fs.Parse(args)
+
+	d, err := timeparse.ParseDate(*date, time.Now())
+	if err != nil {
+		usageError(fs, "%v", err)
+	}
+
+	handleEdit(newApp(ctx, logger, common), d)
+	return nil
+}
+
+func runDelete(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("delete", "[flags]",
+		"Lists your time entries, today's by default, and deletes the ones you tick\nafter asking for confirmation. Locked, approved and invoiced entries are\nskipped.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	date := fs.String("date", "", "Single day: YYYY-MM-DD, yesterday, mon..sun or -2d")
+	from, to := dateRangeFlags(fs)
+	fs.Parse(args)
+
+	if *date != "" && (*from != "" || *to != "") {
+		usageError(fs, "--date cannot be combined with --from or --to")
+	}
+	now := time.Now()
+	day, err := timeparse.ParseDate(*date, now)
+	if err != nil {
+		usageError(fs, "%v", err)
+	}
+	if *from == "" {
+		*from = day.Format(timeparse.DateLayout)
+	}
+	if *to == "" {
+		*to = *from
+	}
+
+	handleDelete(newApp(ctx, logger, common), *from, *to)
+	return nil
+}
+
+func runStatus(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("status", "[flags]",
+		"Prints the running timer as [HH:MM] and the first word of its notes, or\ntoday's billable total when paused. Output defaults to tmux format.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	var sketchyBarMode, waybarMode bool
+	fs.BoolVar(&sketchyBarMode, "b", false, "Format output for SketchyBar (plain text)")
+	fs.BoolVar(&sketchyBarMode, "sketchybar", false, "Same as -b")
+	fs.BoolVar(&waybarMode, "w", false, "Format output for Waybar (JSON)")
+	fs.BoolVar(&waybarMode, "waybar", false, "Same as -w")
+	fs.Parse(args)
+
+	if sketchyBarMode && waybarMode {
+		usageError(fs, "-b and -w cannot be used together")
+	}
+
+	a := newApp(ctx, logger, common)
+	return handleStatusDisplay(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger, sketchyBarMode, waybarMode)
+}
+
+func runToggle(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("toggle", "[flags]",
+		"Stops the running timer, or if none is running restarts the entry from today\nthat was stopped most recently. Prints the same line as status.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	var sketchyBarMode, waybarMode bool
+	fs.BoolVar(&sketchyBarMode, "b", false, "Format output for SketchyBar (plain text)")
+	fs.BoolVar(&sketchyBarMode, "sketchybar", false, "Same as -b")
+	fs.BoolVar(&waybarMode, "w", false, "Format output for Waybar (JSON)")
+	fs.BoolVar(&waybarMode, "waybar", false, "Same as -w")
+	fs.Parse(args)
+
+	if sketchyBarMode && waybarMode {
+		usageError(fs, "-b and -w cannot be used together")
+	}
+
+	a := newApp(ctx, logger, common)
+	return handleToggle(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger, sketchyBarMode, waybarMode)
+}
+
+func runAdd(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("add", "[flags] [-]MINUTES | --set DURATION",
+		"Adds MINUTES to the running timer, or subtracts them when negative. Numbers\nwithout a unit are minutes; give hours as a duration such as -1h15m or 0:45.\n--set replaces the entry's time instead. --entry or --pick change another\nentry.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	set := fs.String("set", "", "Set the entry's time to DURATION (minutes, 1h30m or 1:30)")
+	entryID := fs.Int64("entry", 0, "Change the time entry with this ID")
+	pick := fs.Bool("pick", false, "Pick the time entry from a list")
+	date := fs.String("date", "", "Day to list with --pick: YYYY-MM-DD, yesterday, mon..sun or -2d (default: today)")
+
+	flags, amounts := splitAmounts(fs, args)
+	fs.Parse(flags)
+	amounts = append(amounts, fs.Args()...)
+
+	if *entryID != 0 && *pick {
+		usageError(fs, "--entry and --pick cannot be used together")
+	}
+	adj := timeAdjustment{entryID: *entryID, pick: *pick}
+	if *set != "" {
+		if len(amounts) != 0 {
+			usageError(fs, "--set cannot be combined with MINUTES")
+		}
+		hours, err := parseMinutes(*set)
+		if err != nil {
+			usageError(fs, "--set: %v", err)
+		}
+		if hours < 0 {
+			usageError(fs, "--set must not be negative, got %q", *set)
+		}
+		adj.set = &hours
+	} else {
+		if len(amounts) != 1 {
+			usageError(fs, "add takes exactly one argument: the number of minutes")
+		}
+		delta, err := parseMinutes(amounts[0])
+		if err != nil {
+			usageError(fs, "%v", err)
+		}
+		adj.delta = delta
+		if adj.delta == 0 {
+			usageError(fs, "MINUTES must not be zero")
+		}
+	}
+	d, err := timeparse.ParseDate(*date, time.Now())
+	if err != nil {
+		usageError(fs, "%v", err)
+	}
+	adj.date = d.Format(timeparse.DateLayout)
+
+	a := newApp(ctx, logger, common)
+	return handleAddTime(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger, adj)
+}
+
+// splitAmounts pulls negative amounts such as -15 out of args, since the
+// flag package would take them for flags. Values of flags, as in
+// --date -2d, are left in place.
+func splitAmounts(fs *flag.FlagSet, args []string) (flags, amounts []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(flags, args[i:]...), amounts
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			flags = append(flags, arg)
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		if f := fs.Lookup(name); f != nil {
+			flags = append(flags, arg)
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && b.IsBoolFlag()) && i+1 < len(args) {
+				i++
+				flags = append(flags, args[i])
+			}
+			continue
+		}
+		if arg[1] >= '0' && arg[1] <= '9' || arg[1] == '.' {
+			amounts = append(amounts, arg)
+		} else {
+			flags = append(flags, arg)
+		}
+	}
+	return flags, amounts
+}
+
+func runEntries(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("entries", "[flags]",
+		"Lists your time entries, today's by default. --project, --client and --task\ntake an ID or part of a name.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	from, to := dateRangeFlags(fs)
+	project := fs.String("project", "", "Only entries for this project (ID or name)")
+	client := fs.String("client", "", "Only entries for this client (ID or name)")
+	task := fs.String("task", "", "Only entries for this task (ID or name)")
+	var billable, running, billed optionalBool
+	fs.Var(&billable, "billable", "Only billable entries (--billable=false for non-billable)")
+	fs.Var(&running, "running", "Only running entries (--running=false for stopped)")
+	fs.Var(&billed, "billed", "Only invoiced entries (--billed=false for uninvoiced)")
+	jsonOutput := fs.Bool("json", false, "Output as raw JSON")
+	csvOutput := fs.Bool("csv", false, "Output as CSV")
+	fs.Parse(args)
+
+	if *jsonOutput && *csvOutput {
+		usageError(fs, "--json and --csv cannot be used together")
+	}
+	if *from == "" && *to == "" {
+		today := time.Now().Format("2006-01-02")
+		*from, *to = today, today
+	}
+
+	query := entryQuery{
+		Filter: harvest.TimeEntryFilter{
+			From:      optional(*from),
+			To:        optional(*to),
+			IsRunning: running.value,
+			IsBilled:  billed.value,
+		},
+		Billable: billable.value,
+	}
+	query.Filter.ProjectID, query.Project = idOrName(*project)
+	query.Filter.ClientID, query.Client = idOrName(*client)
+	query.Filter.TaskID, query.Task = idOrName(*task)
+
+	a := newApp(ctx, logger, common)
+	userID := a.currentUserID()
+	query.Filter.UserID = &userID
+	return handleEntryList(a.ctx, os.Stdout, a.client, query, *jsonOutput, *csvOutput)
+}
+
+func runWeek(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("week", "[flags]",
+		"Shows a project and task by day grid of your time for the current week, or\nthe week containing --date, with daily and weekly totals. Working days\nunder the daily target are highlighted.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	date := fs.String("date", "", "Any day in the week: YYYY-MM-DD, mon..sun or -7d (default: today)")
+	target := fs.Float64("target", 0, "Daily target in hours (default: daily_target_hours, or weekly capacity / 5)")
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	csvOutput := fs.Bool("csv", false, "Output as CSV")
+	fs.Parse(args)
+
+	if *jsonOutput && *csvOutput {
+		usageError(fs, "--json and --csv cannot be used together")
+	}
+	if *target < 0 {
+		usageError(fs, "--target must not be negative")
+	}
+	d, err := timeparse.ParseDate(*date, time.Now())
+	if err != nil {
+		usageError(fs, "%v", err)
+	}
+
+	handleWeek(newApp(ctx, logger, common), d, *target, *jsonOutput, *csvOutput)
+	return nil
+}
+
+func runWatch(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := newFlagSet("watch", "[flags]",
+		"Runs until interrupted, watching desktop idle time. When you come back after\nbeing idle for longer than --after with a timer running, it asks whether to\nkeep that time, discard it or split it into a separate entry.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	after := fs.Duration("after", 10*time.Minute, "Idle time that counts as being away")
+	poll := fs.Duration("poll", 15*time.Second, "How often to check idle time")
+	source := fs.String("source", "auto", "Idle source: auto, xprintidle, gnome, or a command printing idle milliseconds")
+	fs.Parse(args)
+
+	if *after <= 0 || *poll <= 0 {
+		usageError(fs, "--after and --poll must be positive")
+	}
+	src, err := idle.Named(*source)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	handleWatch(newApp(ctx, logger, common), src, *after, *poll)
+	return nil
+}
+
+func runExpenses(ctx context.Context, logger *log.Logger, args []string) error {
+	action, args := subcommand(args, "list")
+	switch action {
+	case "list":
+		fs := newFlagSet("expenses list", "[flags]", "Lists expenses.")
+		var common commonOptions
+		addCommonFlags(fs, &common)
+		from, to := dateRangeFlags(fs)
+		jsonOutput := fs.Bool("json", false, "Output as raw JSON")
+		fs.Parse(args)
+
+		a := newApp(ctx, logger, common)
+		return handleExpenseList(a.ctx, os.Stdout, a.client, optional(*from), optional(*to), *jsonOutput)
+	case "create":
+		fs := newFlagSet("expenses create", "[flags]",
+			"Creates an expense. With --project-id, --category-id and --amount it runs\nnon-interactively, otherwise it prompts for the missing details.")
+		var common commonOptions
+		addCommonFlags(fs, &common)
+		projectID := fs.String("project-id", "", "Project ID for expense")
+		categoryID := fs.String("category-id", "", "Expense category ID")
+		amount := fs.String("amount", "", "Expense total cost")
+		date := fs.String("date", "", "Expense date (YYYY-MM-DD, default: today)")
+		notes := fs.String("n", "", "Expense notes")
+		receiptPath := fs.String("receipt", "", "Path to receipt file (PDF/image)")
+		fs.Parse(args)
+
+		a := newApp(ctx, logger, common)
+		return handleExpenseCreate(a.ctx, os.Stdout, a.client, *projectID, *categoryID, *amount, *date, *notes, *receiptPath)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown expenses action %q, expected list or create\n", action)
+		os.Exit(2)
+	}
+	return nil
+}
+
+func runInvoices(ctx context.Context, logger *log.Logger, args []string) error {
+	action, args := subcommand(args, "list")
+	if action != "list" {
+		fmt.Fprintf(os.Stderr, "Unknown invoices action %q, expected list\n", action)
+		os.Exit(2)
+	}
+
+	fs := newFlagSet("invoices list", "[flags]", "Lists invoices.")
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	from, to := dateRangeFlags(fs)
+	jsonOutput := fs.Bool("json", false, "Output as raw JSON")
+	fs.Parse(args)
+
+	a := newApp(ctx, logger, common)
+	return handleInvoiceList(a.ctx, os.Stdout, a.client, optional(*from), optional(*to), *jsonOutput)
+}
+
+func runConfig(ctx context.Context, logger *log.Logger, args []string) error {
+	action, args := subcommand(args, "")
+	switch action {
+	case "setup":
+		fs := newFlagSet("config setup", "[flags]",
+			"Prompts for your Harvest account ID and access token, verifies them and\nsaves them to a profile in "+config.GlobalConfigPath()+".")
+		profileName := fs.String("profile", config.DefaultProfileName, "Name of the profile to create or update")
+		secretStore := fs.String("secret-store", "auto", "Where to keep the access token: auto, plain (the config file), secret-tool, pass, file, or command:<shell> to read it from a command")
+		timeout := fs.Duration("timeout", harvest.DefaultTimeout, "Timeout for each Harvest API request (0 disables)")
+		fs.Parse(args)
+
+		resolved, err := config.Resolve(ctx, config.Flags{Profile: *profileName, IgnoreLocal: true, SkipSecrets: true})
+		if err != nil {
+			logger.Fatalf("Failed to load config: %v", err)
+			os.Exit(1)
+		}
+		if err := setupGlobalConfig(ctx, resolved.Global, *profileName, *secretStore, clientOptions(resolved.Profile, *timeout)); err != nil {
+			fmt.Fprintf(os.Stderr, "Setup failed: %v\n", err)
+			os.Exit(1)
+		}
+	case "show":
+		fs := newFlagSet("config show", "[flags]",
+			"Prints each effective setting and where it came from: a flag, an environment\nvariable, the directory's config, the global config or a default. Secrets are\nmasked.")
+		var opts commonOptions
+		addCommonFlags(fs, &opts)
+		fs.Parse(args)
+
+		timeout := config.Setting{Name: "timeout", Value: opts.timeout.String()}
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "timeout" {
+				timeout.Source, timeout.Origin = config.SourceFlag, "--timeout"
+			}
+		})
+		handleConfigShow(resolveConfig(ctx, logger, opts), timeout)
+	case "profiles":
+		fs := newFlagSet("config profiles", "",
+			"Lists the profiles in "+config.GlobalConfigPath()+". The default profile is\nmarked with *.")
+		fs.Parse(args)
+
+		global, err := config.LoadGlobal()
+		if err != nil {
+			logger.Fatalf("Failed to load global config: %v", err)
+			os.Exit(1)
+		}
+		if len(global.Profiles) == 0 {
+			fmt.Println("No profiles configured. Run 'harvest_cli config setup' to add one.")
+			return nil
+		}
+		for _, name := range global.ProfileNames() {
+			marker := " "
+			if name == global.DefaultProfile {
+				marker = "*"
+			}
+			fmt.Printf("%s %-16s account %s\n", marker, name, global.Profiles[name].HarvestAccountID)
+		}
+	case "use":
+		fs := newFlagSet("config use", "<profile>",
+			"Makes profile the default for directories that don't pin one.")
+		fs.Parse(args)
+		if fs.NArg() != 1 {
+			usageError(fs, "config use takes exactly one profile name")
+		}
+
+		global, err := config.LoadGlobal()
+		if err != nil {
+			logger.Fatalf("Failed to load global config: %v", err)
+			os.Exit(1)
+		}
+		name := fs.Arg(0)
+		if global.Profiles[name] == nil {
+			fmt.Fprintln(os.Stderr, global.UnknownProfileError(name))
+			os.Exit(1)
+		}
+		global.DefaultProfile = name
+		if err := global.Save(); err != nil {
+			logger.Fatalf("Failed to save global config: %v", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Default profile is now %q.\n", name)
+	default:
+		fmt.Fprintln(os.Stderr, "Usage: harvest_cli config <action>")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Actions:")
+		fmt.Fprintln(os.Stderr, "  setup      Enter and verify your Harvest credentials")
+		fmt.Fprintln(os.Stderr, "  show       Print the effective settings and where they come from")
+		fmt.Fprintln(os.Stderr, "  profiles   List the configured profiles")
+		fmt.Fprintln(os.Stderr, "  use        Change the default profile")
+		if action != "" {
+			os.Exit(2)
+		}
+	}
+	return nil
+}
+
+func runHelp(ctx context.Context, logger *log.Logger, args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return nil
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil || cmd.name == "help" {
+		printUsage()
+		return nil
+	}
+	return cmd.run(ctx, logger, append(args[1:], "-h"))
+}
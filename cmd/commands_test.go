@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestParseMinutes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "2", want: 2.0 / 60},
+		{in: "90", want: 1.5},
+		{in: "-45", want: -0.75},
+		{in: "1h30m", want: 1.5},
+		{in: "-1h15m", want: -1.25},
+		{in: "90m", want: 1.5},
+		{in: "1:30", want: 1.5},
+		{in: "0:45", want: 0.75},
+		{in: "1.5", wantErr: true},
+		{in: "-.5", wantErr: true},
+		{in: "1:5", wantErr: true},
+		{in: "lunch", wantErr: true},
+		{in: "-1440", want: -24},
+		{in: "1441", wantErr: true},
+		{in: "25h", wantErr: true},
+		{in: "inf", wantErr: true},
+		{in: "nan", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseMinutes(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseMinutes(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseMinutes(%q): %v", tt.in, err)
+		} else if got != tt.want {
+			t.Errorf("parseMinutes(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitAmounts(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantFlags   []string
+		wantAmounts []string
+	}{
+		{args: []string{"-15"}, wantAmounts: []string{"-15"}},
+		{args: []string{"--pick", "-1h30m"}, wantFlags: []string{"--pick"}, wantAmounts: []string{"-1h30m"}},
+		{args: []string{"--date", "-2d", "--pick", "30"}, wantFlags: []string{"--date", "-2d", "--pick", "30"}},
+		{args: []string{"--date=-2d", "-30"}, wantFlags: []string{"--date=-2d"}, wantAmounts: []string{"-30"}},
+		{args: []string{"--set", "-30"}, wantFlags: []string{"--set", "-30"}},
+		{args: []string{"-entry", "12", "-.5"}, wantFlags: []string{"-entry", "12"}, wantAmounts: []string{"-.5"}},
+		{args: []string{"--", "-15"}, wantFlags: []string{"--", "-15"}},
+		{args: []string{"--unknown", "-15"}, wantFlags: []string{"--unknown"}, wantAmounts: []string{"-15"}},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("add", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("set", "", "")
+		fs.Int64("entry", 0, "")
+		fs.Bool("pick", false, "")
+		fs.String("date", "", "")
+		flags, amounts := splitAmounts(fs, tt.args)
+		if !slices.Equal(flags, tt.wantFlags) || !slices.Equal(amounts, tt.wantAmounts) {
+			t.Errorf("splitAmounts(%q) = %q, %q, want %q, %q", tt.args, flags, amounts, tt.wantFlags, tt.wantAmounts)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/example/harvestcli/internal/config"
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+// defaultDescriptions describe what happens when a setting isn't set
+// anywhere.
+var defaultDescriptions = map[string]string{
+	"account_id":         "(not set)",
+	"access_token":       "(not set)",
+	"user_id":            "(looked up from your token)",
+	"base_url":           harvest.DefaultBaseURL,
+	"daily_target_hours": "(weekly capacity / 5)",
+	"branch":             "(not on a branch)",
+	"project_id":         "(prompted)",
+	"task_id":            "(prompted)",
+	"ticket":             "(none)",
+}
+
+// handleConfigShow prints each effective setting with its source, masking
+// secrets. timeout is reported alongside as it only comes from a flag.
+func handleConfigShow(resolved *config.Resolved, timeout config.Setting) {
+	fmt.Printf("%-20s %-36s %s\n", "SETTING", "VALUE", "SOURCE")
+	for _, s := range append(resolved.Settings, timeout) {
+		value := s.Value
+		switch {
+		case s.Source == config.SourceDefault && value == "":
+			value = defaultDescriptions[s.Name]
+		case s.Secret:
+			value = config.Mask(value)
+		}
+		source := s.Source.String()
+		if s.Origin != "" {
+			source += " (" + s.Origin + ")"
+		}
+		fmt.Printf("%-20s %-36s %s\n", s.Name, value, source)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/prompt"
+)
+
+// handleDelete lists the entries between from and to, lets the user tick the
+// ones to delete and deletes them after confirmation. Locked, approved and
+// invoiced entries are left out of the list since Harvest would refuse them.
+func handleDelete(a *app, from, to string) {
+	userID := a.currentUserID()
+	entries, err := a.client.ListTimeEntries(a.ctx, harvest.TimeEntryFilter{From: &from, To: &to, UserID: &userID})
+	if err != nil {
+		fatalAPI(a.logger, "Failed to list time entries", err)
+	}
+
+	var deletable []harvest.TimeEntry
+	for _, entry := range entries {
+		if reason := entryLockReason(entry); reason != "" {
+			fmt.Printf("Skipping time entry %d (%s - %s on %s): %s.\n",
+				entry.ID, entry.Project.Name, entry.Task.Name, entry.SpentDate, reason)
+			continue
+		}
+		deletable = append(deletable, entry)
+	}
+	if len(deletable) == 0 {
+		if len(entries) == 0 {
+			fmt.Println("No time entries found.")
+		} else {
+			fmt.Println("No time entries can be deleted.")
+		}
+		return
+	}
+
+	options := make([]string, len(deletable))
+	for i, entry := range deletable {
+		options[i] = formatEntryOption(entry)
+		if from != to {
+			options[i] = entry.SpentDate + "  " + options[i]
+		}
+	}
+	picked, err := prompt.MultiSelectPrompt(options, "Select time entries to delete:")
+	if err != nil {
+		a.logger.Fatalf("Selection error: %v", err)
+		os.Exit(1)
+	}
+	if len(picked) == 0 {
+		fmt.Println("Nothing selected.")
+		return
+	}
+
+	var total float64
+	for _, i := range picked {
+		total += deletable[i].Hours
+	}
+	hours, minutes := splitHours(total)
+	noun := "time entries"
+	if len(picked) == 1 {
+		noun = "time entry"
+	}
+	ok, err := prompt.ConfirmPrompt(fmt.Sprintf("Delete %d %s totalling [%02d:%02d]?", len(picked), noun, hours, minutes))
+	if err != nil {
+		a.logger.Fatalf("prompt error: %v", err)
+		os.Exit(1)
+	}
+	if !ok {
+		fmt.Println("Nothing deleted.")
+		return
+	}
+
+	for _, i := range picked {
+		entry := deletable[i]
+		if err := a.client.DeleteTimeEntry(a.ctx, entry.ID); err != nil {
+			fatalAPI(a.logger, fmt.Sprintf("Failed to delete time entry %d", entry.ID), err)
+		}
+		hours, minutes := splitHours(entry.Hours)
+		fmt.Printf("Deleted time entry %d for project %s task %s on %s [%02d:%02d]\n",
+			entry.ID, entry.Project.Name, entry.Task.Name, entry.SpentDate, hours, minutes)
+	}
+}
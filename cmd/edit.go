@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/prompt"
+	"github.com/example/harvestcli/internal/timeparse"
+)
+
+// entryEdit tracks the changes made in the edit form. update holds only the
+// fields that differ from the original entry; entry shows the edited values.
+type entryEdit struct {
+	original harvest.TimeEntry
+	entry    harvest.TimeEntry
+	update   harvest.TimeEntryUpdateRequest
+}
+
+func (e *entryEdit) setProject(pa harvest.ProjectAssignment, task harvest.Task) {
+	e.entry.Project, e.entry.Client, e.entry.Task = pa.Project, pa.Client, task
+	e.update.ProjectID = changed(pa.Project.ID, e.original.Project.ID)
+	e.update.TaskID = changed(task.ID, e.original.Task.ID)
+}
+
+func (e *entryEdit) setNotes(notes string) {
+	e.entry.Notes = &notes
+	orig := ""
+	if e.original.Notes != nil {
+		orig = *e.original.Notes
+	}
+	e.update.Notes = changed(notes, orig)
+}
+
+func (e *entryEdit) setDate(date string) {
+	e.entry.SpentDate = date
+	e.update.SpentDate = changed(date, e.original.SpentDate)
+}
+
+func (e *entryEdit) setHours(hours float64) {
+	e.entry.Hours = hours
+	e.update.Hours = changed(hours, e.original.Hours)
+}
+
+func (e *entryEdit) setTimes(started, ended string, hours float64) {
+	e.entry.StartedTime, e.entry.EndedTime, e.entry.Hours = &started, &ended, hours
+	if e.original.StartedTime != nil && e.original.EndedTime != nil &&
+		started == *e.original.StartedTime && ended == *e.original.EndedTime {
+		e.update.StartedTime, e.update.EndedTime = nil, nil
+		return
+	}
+	e.update.StartedTime, e.update.EndedTime = &started, &ended
+}
+
+// changed returns a pointer to v, or nil when it equals the original value so
+// the field is left out of the update.
+func changed[T comparable](v, original T) *T {
+	if v == original {
+		return nil
+	}
+	return &v
+}
+
+// handleEdit lets the user pick one of the day's entries and change its
+// project, task, notes, date and hours or start and end times, then sends
+// only the changed fields.
+func handleEdit(a *app, date time.Time) {
+	day := date.Format(timeparse.DateLayout)
+	userID := a.currentUserID()
+	entries, err := a.client.ListTimeEntries(a.ctx, harvest.TimeEntryFilter{From: &day, To: &day, UserID: &userID})
+	if err != nil {
+		fatalAPI(a.logger, "Failed to list time entries", err)
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No time entries found for %s.\n", day)
+		return
+	}
+
+	entryOptions := make([]string, len(entries))
+	for i, entry := range entries {
+		entryOptions[i] = formatEntryOption(entry)
+	}
+	idx, err := prompt.SelectPrompt(entryOptions, "Select a time entry to edit:")
+	if err != nil {
+		a.logger.Fatalf("Selection error: %v", err)
+		os.Exit(1)
+	}
+	selected := entries[idx]
+	if reason := entryLockReason(selected); reason != "" {
+		fmt.Fprintf(os.Stderr, "Time entry %d can't be edited: %s.\n", selected.ID, reason)
+		os.Exit(exitLocked)
+	}
+
+	company, err := a.client.Company(a.ctx)
+	if err != nil {
+		fatalAPI(a.logger, "Failed to fetch account settings", err)
+	}
+
+	edit := &entryEdit{original: selected, entry: selected}
+	var assignments []harvest.ProjectAssignment
+	loadAssignments := func() []harvest.ProjectAssignment {
+		if assignments == nil {
+			assignments, err = a.client.ListMyProjectAssignments(a.ctx)
+			if err != nil {
+				fatalAPI(a.logger, "Failed to list project assignments", err)
+			}
+		}
+		return assignments
+	}
+
+	for {
+		fields := []string{
+			fmt.Sprintf("Project: %s \033[36m(%s)\033[0m", edit.entry.Project.Name, edit.entry.Client.Name),
+			"Task:    " + edit.entry.Task.Name,
+			"Notes:   " + firstLine(edit.entry.Notes),
+			"Date:    " + edit.entry.SpentDate,
+		}
+		if company.WantsTimestampTimers {
+			fields = append(fields, "Time:    "+entryRange(edit.entry))
+		} else {
+			hours, minutes := splitHours(edit.entry.Hours)
+			fields = append(fields, fmt.Sprintf("Hours:   %d:%02d", hours, minutes))
+		}
+		save := len(fields)
+		fields = append(fields, "Save changes", "Cancel")
+
+		choice, err := prompt.SelectPrompt(fields, fmt.Sprintf("Editing time entry %d:", selected.ID))
+		if err != nil {
+			a.logger.Fatalf("prompt error: %v", err)
+			os.Exit(1)
+		}
+
+		switch choice {
+		case 0:
+			if len(loadAssignments()) == 0 {
+				fmt.Println("You are not assigned to any active projects.")
+				continue
+			}
+			pa, err := selectAssignment(a, assignments, 0, false)
+			if err != nil {
+				exit(a.logger, err)
+			}
+			task, ok, err := selectTask(a, pa, 0)
+			if err != nil {
+				exit(a.logger, err)
+			}
+			if !ok {
+				continue
+			}
+			edit.setProject(*pa, task)
+		case 1:
+			var current *harvest.ProjectAssignment
+			for i, pa := range loadAssignments() {
+				if pa.Project.ID == edit.entry.Project.ID {
+					current = &assignments[i]
+					break
+				}
+			}
+			if current == nil {
+				fmt.Printf("You are no longer assigned to project %s; pick a project first.\n", edit.entry.Project.Name)
+				continue
+			}
+			task, ok, err := selectTask(a, current, 0)
+			if err != nil {
+				exit(a.logger, err)
+			}
+			if !ok {
+				continue
+			}
+			edit.setProject(*current, task)
+		case 2:
+			notes := ""
+			if edit.entry.Notes != nil {
+				notes = *edit.entry.Notes
+			}
+			notes, err = prompt.TextAreaPrompt("Notes:", notes)
+			if err != nil {
+				a.logger.Fatalf("prompt error: %v", err)
+				os.Exit(1)
+			}
+			edit.setNotes(notes)
+		case 3:
+			input, err := prompt.InputPrompt("Date (YYYY-MM-DD, yesterday, mon..sun or -2d):", edit.entry.SpentDate)
+			if err != nil {
+				a.logger.Fatalf("prompt error: %v", err)
+				os.Exit(1)
+			}
+			d, err := timeparse.ParseDate(input, time.Now())
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			edit.setDate(d.Format(timeparse.DateLayout))
+		case 4:
+			if company.WantsTimestampTimers {
+				input, err := prompt.InputPrompt("Start and end (09:00-10:30):", entryRange(edit.entry))
+				if err != nil {
+					a.logger.Fatalf("prompt error: %v", err)
+					os.Exit(1)
+				}
+				span, err := timeparse.ParseSpan(input)
+				if err == nil && !span.IsRange {
+					err = fmt.Errorf("give a range such as 09:00-10:30")
+				}
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				edit.setTimes(timeparse.FormatClock(span.Start, company.ClockFormat), timeparse.FormatClock(span.End, company.ClockFormat), span.Hours)
+			} else {
+				hours, minutes := splitHours(edit.entry.Hours)
+				input, err := prompt.InputPrompt("Hours (1h30m, 90m, 1.5 or 1:30):", fmt.Sprintf("%d:%02d", hours, minutes))
+				if err != nil {
+					a.logger.Fatalf("prompt error: %v", err)
+					os.Exit(1)
+				}
+				h, err := timeparse.ParseHours(input)
+				if err == nil && h < 0 {
+					err = fmt.Errorf("hours can't be negative")
+				}
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				edit.setHours(h)
+			}
+		case save:
+			if edit.update.IsEmpty() {
+				fmt.Println("No changes to save.")
+				return
+			}
+			updated, err := a.client.UpdateTimeEntry(a.ctx, selected.ID, edit.update)
+			if err != nil {
+				fatalAPI(a.logger, "Failed to update time entry", err)
+			}
+			hours, minutes := splitHours(updated.Hours)
+			fmt.Printf("Updated time entry %d: project %s task %s on %s [%02d:%02d]\n",
+				updated.ID, updated.Project.Name, updated.Task.Name, updated.SpentDate, hours, minutes)
+			return
+		default:
+			fmt.Println("Edit cancelled.")
+			return
+		}
+	}
+}
+
+// firstLine returns the first line of notes, marking any that follow.
+func firstLine(notes *string) string {
+	if notes == nil || *notes == "" {
+		return "\033[90m(none)\033[0m"
+	}
+	line, rest, more := strings.Cut(strings.TrimSpace(*notes), "\n")
+	if more && strings.TrimSpace(rest) != "" {
+		return line + " \033[90m…\033[0m"
+	}
+	return line
+}
+
+// entryRange formats an entry's start and end times as START-END.
+func entryRange(entry harvest.TimeEntry) string {
+	if entry.StartedTime == nil || entry.EndedTime == nil {
+		return ""
+	}
+	return *entry.StartedTime + "-" + *entry.EndedTime
+}
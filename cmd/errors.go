@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+// Exit codes let scripts tell failure classes apart.
+const (
+	exitError        = 1
+	exitUnauthorized = 3
+	exitNotFound     = 4
+	exitLocked       = 5
+	exitRateLimited  = 6
+	exitInterrupted  = 130
+)
+
+// cliError is a failure reported as msg, exiting with code.
+type cliError struct {
+	code int
+	msg  string
+}
+
+func (e *cliError) Error() string { return e.msg }
+
+// apiError is a failed Harvest call and what was being attempted.
+type apiError struct {
+	action string
+	err    error
+}
+
+func (e *apiError) Error() string { return fmt.Sprintf("%s: %v", e.action, e.err) }
+func (e *apiError) Unwrap() error { return e.err }
+
+// exitStatus returns the exit code for err and, for Harvest failures, an
+// actionable hint.
+func exitStatus(err error) (code int, hint string) {
+	var cliErr *cliError
+	switch {
+	case errors.As(err, &cliErr):
+		return cliErr.code, ""
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted, "Interrupted."
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitError, "Harvest did not respond in time. Try again or raise -timeout."
+	case harvest.IsUnauthorized(err):
+		return exitUnauthorized, "Your Harvest access token was rejected. Check it at https://id.getharvest.com/developers and run 'harvest_cli config setup' to replace it."
+	case harvest.IsForbidden(err):
+		return exitUnauthorized, "Your Harvest user is not allowed to do this. Ask an administrator for access."
+	case harvest.IsNotFound(err):
+		return exitNotFound, "It may have been deleted in Harvest."
+	case harvest.IsLocked(err):
+		return exitLocked, "The entry is locked, approved or already invoiced."
+	case harvest.IsRateLimited(err):
+		return exitRateLimited, "Harvest is rate limiting requests. Wait a few seconds and try again."
+	}
+	return exitError, ""
+}
+
+// report logs err and prints it and any hint to stderr, returning the exit
+// code for it.
+func report(logger *log.Logger, err error) int {
+	logger.Print(err)
+	code, hint := exitStatus(err)
+	fmt.Fprintln(os.Stderr, err)
+	if hint != "" {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+	return code
+}
+
+// exit reports err and exits with a code matching the kind of failure.
+func exit(logger *log.Logger, err error) {
+	os.Exit(report(logger, err))
+}
+
+// fatalAPI reports a failed Harvest call and exits; see exit.
+func fatalAPI(logger *log.Logger, action string, err error) {
+	exit(logger, &apiError{action: action, err: err})
+}
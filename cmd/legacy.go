@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// runLegacy handles invocations without a command. Plain runs (optionally
+// with -n, -t or -l) start a timer; the single-letter mode flags are kept as
+// deprecated aliases for the matching commands.
+func runLegacy(ctx context.Context, logger *log.Logger, args []string) error {
+	fs := flag.NewFlagSet("harvest_cli", flag.ExitOnError)
+	fs.Usage = func() {
+		printUsage()
+		fmt.Fprintln(os.Stderr, "\nFlags when no command is given (mode flags are deprecated):")
+		fs.PrintDefaults()
+	}
+
+	var common commonOptions
+	addCommonFlags(fs, &common)
+	var start startOptions
+	fs.StringVar(&start.note, "n", "", "Initial notes text")
+	fs.StringVar(&start.ticket, "t", "", "External ticket number to prefix notes")
+	fs.BoolVar(&start.lazy, "l", false, "Lazy project selection (hide list until typing)")
+
+	var selectEntry bool
+	var showStatus bool
+	var sketchyBarMode bool
+	var waybarMode bool
+	var stopTimer bool
+	var addMinutes int
+	var listInvoices bool
+	var listExpenses bool
+	var fromDate string
+	var toDate string
+	var jsonOutput bool
+	var createExpense bool
+	var expenseProjectID string
+	var expenseCategoryID string
+	var expenseAmount string
+	var expenseDate string
+	var receiptPath string
+	fs.BoolVar(&selectEntry, "e", false, "Select and restart an existing time entry (deprecated: use 'restart')")
+	fs.BoolVar(&showStatus, "s", false, "Show current running timer status (deprecated: use 'status')")
+	fs.BoolVar(&sketchyBarMode, "b", false, "Format output for SketchyBar (deprecated: use 'status -b')")
+	fs.BoolVar(&waybarMode, "w", false, "Format output for Waybar (deprecated: use 'status -w')")
+	fs.BoolVar(&stopTimer, "q", false, "Stop the currently running timer (deprecated: use 'stop')")
+	fs.IntVar(&addMinutes, "a", 0, "Add minutes to current running timer (deprecated: use 'add')")
+	fs.BoolVar(&listInvoices, "I", false, "List recent invoices (deprecated: use 'invoices list')")
+	fs.BoolVar(&listExpenses, "E", false, "List expenses (deprecated: use 'expenses list')")
+	fs.StringVar(&fromDate, "from", "", "From date (YYYY-MM-DD)")
+	fs.StringVar(&toDate, "to", "", "To date (YYYY-MM-DD)")
+	fs.BoolVar(&jsonOutput, "json", false, "Output as raw JSON")
+	fs.BoolVar(&createExpense, "create", false, "Create a new expense (deprecated: use 'expenses create')")
+	fs.StringVar(&expenseProjectID, "project-id", "", "Project ID for expense")
+	fs.StringVar(&expenseCategoryID, "category-id", "", "Expense category ID")
+	fs.StringVar(&expenseAmount, "amount", "", "Expense total cost")
+	fs.StringVar(&expenseDate, "date", "", "Expense date (YYYY-MM-DD, default: today)")
+	fs.StringVar(&receiptPath, "receipt", "", "Path to receipt file (PDF/image)")
+	fs.Parse(args)
+
+	// Validate flags
+	if sketchyBarMode && !showStatus {
+		logger.Fatalf("-b flag must be used with -s flag")
+		os.Exit(1)
+	}
+	if waybarMode && !showStatus {
+		logger.Fatalf("-w flag must be used with -s flag")
+		os.Exit(1)
+	}
+	if sketchyBarMode && waybarMode {
+		logger.Fatalf("-b and -w flags cannot be used together")
+		os.Exit(1)
+	}
+
+	// Validate add minutes flag
+	if addMinutes < 0 {
+		logger.Fatalf("-a flag must be a positive number of minutes")
+		os.Exit(1)
+	}
+
+	// Check for conflicting flags with -a
+	if addMinutes > 0 && (selectEntry || showStatus || stopTimer) {
+		logger.Fatalf("-a flag cannot be used with -e, -s, or -q flags")
+		os.Exit(1)
+	}
+
+	// Validate --create flag
+	if createExpense && !listExpenses {
+		logger.Fatalf("--create flag must be used with -E flag")
+		os.Exit(1)
+	}
+
+	var from, to *string
+	if fromDate != "" {
+		from = &fromDate
+	}
+	if toDate != "" {
+		to = &toDate
+	}
+
+	switch {
+	case stopTimer:
+		deprecated(logger, "-q", "stop")
+		a := newApp(ctx, logger, common)
+		return handleStopTimer(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger)
+	case selectEntry:
+		deprecated(logger, "-e", "restart")
+		a := newApp(ctx, logger, common)
+		return handleTimeEntrySelection(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger)
+	case showStatus:
+		deprecated(logger, "-s", "status")
+		a := newApp(ctx, logger, common)
+		return handleStatusDisplay(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger, sketchyBarMode, waybarMode)
+	case addMinutes > 0:
+		deprecated(logger, "-a", "add")
+		a := newApp(ctx, logger, common)
+		return handleAddTime(a.ctx, os.Stdout, a.client, a.currentUserID(), a.logger, timeAdjustment{delta: float64(addMinutes) / 60})
+	case listInvoices:
+		deprecated(logger, "-I", "invoices list")
+		a := newApp(ctx, logger, common)
+		return handleInvoiceList(a.ctx, os.Stdout, a.client, from, to, jsonOutput)
+	case listExpenses && createExpense:
+		deprecated(logger, "-E --create", "expenses create")
+		a := newApp(ctx, logger, common)
+		return handleExpenseCreate(a.ctx, os.Stdout, a.client, expenseProjectID, expenseCategoryID, expenseAmount, expenseDate, withTicket(start.note, start.ticket), receiptPath)
+	case listExpenses:
+		deprecated(logger, "-E", "expenses list")
+		a := newApp(ctx, logger, common)
+		return handleExpenseList(a.ctx, os.Stdout, a.client, from, to, jsonOutput)
+	default:
+		return handleStart(newApp(ctx, logger, common), os.Stdout, start)
+	}
+}
+
+// deprecated notes the use of a legacy flag. The notice goes to the log, and
+// to stderr only when it is a terminal so status bar scripts stay quiet.
+func deprecated(logger *log.Logger, flagName, command string) {
+	msg := fmt.Sprintf("%s is deprecated, use `harvest_cli %s` instead", flagName, command)
+	logger.Print(msg)
+	if stderrIsTerminal() {
+		fmt.Fprintln(os.Stderr, "Note: "+msg)
+	}
+}
@@ -1,24 +1,37 @@
 package main
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
-	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/example/harvestcli/internal/config"
 	"github.com/example/harvestcli/internal/harvest"
 	"github.com/example/harvestcli/internal/prompt"
+	"github.com/example/harvestcli/internal/timeparse"
 )
 
-func setupGlobalConfig(cfg *config.Config) error {
-	fmt.Println("Harvest CLI needs to be configured. Please provide the following information:")
+// setupGlobalConfig prompts for and verifies the credentials of a profile and
+// saves them. The access token goes to secretStore: "auto" to detect one,
+// config.PlainStore for the config file, the name of a config.SecretStore,
+// or a "command:<shell>" reference to read it from instead of prompting.
+func setupGlobalConfig(ctx context.Context, global *config.Global, profileName, secretStore string, clientOpts []harvest.Option) error {
+	if profileName == config.DefaultProfileName && len(global.Profiles) == 0 {
+		fmt.Println("Harvest CLI needs to be configured. Please provide the following information:")
+	} else {
+		fmt.Printf("Setting up profile %q. Please provide the following information:\n", profileName)
+	}
 	fmt.Println()
 
 	// Prompt for account ID
@@ -30,31 +43,48 @@ func setupGlobalConfig(cfg *config.Config) error {
 		return fmt.Errorf("account ID cannot be empty")
 	}
 
-	// Prompt for access token
-	accessToken, err := prompt.InputPrompt("Harvest Access Token:", "")
-	if err != nil {
-		return fmt.Errorf("failed to get access token: %v", err)
-	}
-	if accessToken == "" {
-		return fmt.Errorf("access token cannot be empty")
+	// Prompt for access token, unless a command provides it
+	var accessToken string
+	if strings.HasPrefix(secretStore, "command:") {
+		accessToken, err = config.ReadSecret(ctx, secretStore)
+		if err != nil {
+			return fmt.Errorf("failed to get access token: %w", err)
+		}
+	} else {
+		accessToken, err = prompt.InputPrompt("Harvest Access Token:", "")
+		if err != nil {
+			return fmt.Errorf("failed to get access token: %v", err)
+		}
+		if accessToken == "" {
+			return fmt.Errorf("access token cannot be empty")
+		}
 	}
 
-	// Prompt for user ID
-	userID, err := prompt.InputPrompt("Harvest User ID:", "")
+	// Validate the token and look up the user it belongs to
+	client, err := harvest.NewClient(accountID, accessToken, clientOpts...)
 	if err != nil {
-		return fmt.Errorf("failed to get user ID: %v", err)
+		return err
 	}
-	if userID == "" {
-		return fmt.Errorf("user ID cannot be empty")
+	me, err := client.Me(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify credentials: %w", err)
 	}
+	fmt.Printf("Authenticated as %s (%s)\n", me.Name(), me.Email)
 
 	// Update config
-	cfg.HarvestAccountID = accountID
-	cfg.HarvestAccessToken = accessToken
-	cfg.HarvestUserID = userID
+	profile := global.Profiles[profileName]
+	if profile == nil {
+		profile = &config.Profile{}
+	}
+	profile.HarvestAccountID = accountID
+	profile.HarvestUserID = strconv.FormatInt(me.ID, 10)
+	if err := storeAccessToken(ctx, profile, profileName, secretStore, accessToken); err != nil {
+		return err
+	}
+	global.SetProfile(profileName, profile)
 
 	// Save config
-	if err := cfg.SaveGlobal(); err != nil {
+	if err := global.Save(); err != nil {
 		return fmt.Errorf("failed to save global config: %v", err)
 	}
 
@@ -62,26 +92,61 @@ func setupGlobalConfig(cfg *config.Config) error {
 	return nil
 }
 
-func handleStopTimer(client *harvest.Client, userIDStr string, logger *log.Logger) {
-	if userIDStr == "" {
-		logger.Fatalf("User ID must be provided")
-		os.Exit(1)
+// storeAccessToken puts token in secretStore and points profile at it. A
+// detected store that fails falls back to the config file.
+func storeAccessToken(ctx context.Context, profile *config.Profile, profileName, secretStore, token string) error {
+	profile.HarvestAccessToken, profile.HarvestAccessTokenRef = "", ""
+	if strings.HasPrefix(secretStore, "command:") {
+		profile.HarvestAccessTokenRef = secretStore
+		return nil
+	}
+
+	auto := secretStore == "auto"
+	if auto {
+		secretStore = config.DetectSecretStore()
+	}
+	if secretStore != config.PlainStore {
+		ref, err := config.WriteSecret(ctx, secretStore, config.SecretKey(secretStore, profileName), token)
+		if err == nil {
+			profile.HarvestAccessTokenRef = ref
+			fmt.Printf("Access token saved to %s.\n", secretStore)
+			return nil
+		}
+		if !auto {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Could not save the access token to %s (%v); keeping it in the config file.\n", secretStore, err)
+	}
+	profile.HarvestAccessToken = token
+	return nil
+}
+
+// resolveUserID returns the current user's ID, looking it up via /users/me
+// and caching it in the global config when it is missing or malformed.
+func resolveUserID(ctx context.Context, client harvest.API, resolved *config.Resolved, logger *log.Logger) int64 {
+	if id, err := strconv.ParseInt(resolved.Profile.HarvestUserID, 10, 64); err == nil && id > 0 {
+		return id
 	}
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	me, err := client.Me(ctx)
 	if err != nil {
-		logger.Fatalf("Invalid user ID: %v", err)
-		os.Exit(1)
+		fatalAPI(logger, "Failed to look up current user", err)
+	}
+	if err := resolved.CacheUserID(strconv.FormatInt(me.ID, 10)); err != nil {
+		logger.Printf("Failed to cache user ID: %v", err)
 	}
+	return me.ID
+}
 
+// handleStopTimer stops the running timer.
+func handleStopTimer(ctx context.Context, w io.Writer, client harvest.API, userID int64, logger *log.Logger) error {
 	// Get today's date for filtering
 	today := time.Now().Format("2006-01-02")
 
 	// List time entries for today filtered by current user
-	entries, err := client.ListTimeEntries(&today, &today, &userID)
+	entries, err := client.ListTimeEntries(ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
 	if err != nil {
-		logger.Fatalf("Failed to list time entries: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list time entries", err: err}
 	}
 
 	// Find running entries
@@ -94,134 +159,130 @@ func handleStopTimer(client *harvest.Client, userIDStr string, logger *log.Logge
 	}
 
 	if runningEntry == nil {
-		fmt.Println("No running timer found to stop.")
-		return
+		fmt.Fprintln(w, "No running timer found to stop.")
+		return nil
 	}
 
 	// Stop the time entry
-	stoppedEntry, err := client.StopTimeEntry(runningEntry.ID)
+	stoppedEntry, err := client.StopTimeEntry(ctx, runningEntry.ID)
 	if err != nil {
-		logger.Fatalf("Failed to stop time entry: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to stop time entry", err: err}
 	}
 
-	fmt.Printf("Stopped time entry %d for project %s task %s\n",
+	fmt.Fprintf(w, "Stopped time entry %d for project %s task %s\n",
 		stoppedEntry.ID, stoppedEntry.Project.Name, stoppedEntry.Task.Name)
+	return nil
 }
 
-func handleTimeEntrySelection(client *harvest.Client, userIDStr string, logger *log.Logger) {
-	if userIDStr == "" {
-		logger.Fatalf("User ID must be provided")
-		os.Exit(1)
-	}
-
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		logger.Fatalf("Invalid user ID: %v", err)
-		os.Exit(1)
-	}
-
+// handleTimeEntrySelection restarts the entry picked from today's list.
+func handleTimeEntrySelection(ctx context.Context, w io.Writer, client harvest.API, userID int64, logger *log.Logger) error {
 	// Get today's date for filtering
 	today := time.Now().Format("2006-01-02")
 
 	// List time entries for today filtered by current user
-	entries, err := client.ListTimeEntries(&today, &today, &userID)
+	entries, err := client.ListTimeEntries(ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
 	if err != nil {
-		logger.Fatalf("Failed to list time entries: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list time entries", err: err}
 	}
 
 	if len(entries) == 0 {
-		fmt.Println("No time entries found for today.")
-		return
+		fmt.Fprintln(w, "No time entries found for today.")
+		return nil
 	}
 
 	// Create options for selection
 	entryOptions := make([]string, len(entries))
 	for i, entry := range entries {
-		status := "\033[33mStopped\033[0m" // Yellow for stopped
-		if entry.IsRunning {
-			status = "\033[32mRunning\033[0m" // Green for running
-		}
-		notes := ""
-		if entry.Notes != nil {
-			// Replace newlines with spaces and clean up formatting
-			notes = strings.ReplaceAll(*entry.Notes, "\n", " | ")
-			notes = strings.ReplaceAll(notes, "\r", " | ")
-			// Trim whitespace and add padding
-			notes = strings.TrimSpace(notes)
-			// Truncate very long notes to prevent wrapping issues
-			if len(notes) > 60 {
-				notes = notes[:57] + "..."
-			}
-			if notes != "" {
-				// Add cyan color highlighting for notes
-				notes = fmt.Sprintf("  \033[36m%s\033[0m", notes)
-			}
-		}
-
-		// Convert decimal hours to [HH:MM] format
-		totalHours := entry.Hours
-		hours := int(totalHours)
-		minutes := int(math.Ceil((totalHours - float64(hours)) * 60))
-		// Handle case where minutes rounds up to 60 (should increment hours)
-		if minutes >= 60 {
-			hours++
-			minutes = 0
-		}
-
-		entryOptions[i] = fmt.Sprintf("%s - %s (%s) [%02d:%02d]%s",
-			entry.Project.Name, entry.Task.Name, status, hours, minutes, notes)
+		entryOptions[i] = formatEntryOption(entry)
 	}
 
 	// Show selection prompt
 	idx, err := prompt.SelectPrompt(entryOptions, "Select a time entry to restart:")
 	if err != nil {
-		logger.Fatalf("Selection error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("selection error: %w", err)
 	}
 
 	selectedEntry := entries[idx]
 
 	// Check if entry is already running
 	if selectedEntry.IsRunning {
-		fmt.Printf("Time entry %d is already running.\n", selectedEntry.ID)
-		return
+		fmt.Fprintf(w, "Time entry %d is already running.\n", selectedEntry.ID)
+		return nil
 	}
 
 	// Restart the time entry
-	restartedEntry, err := client.RestartTimeEntry(selectedEntry.ID)
+	restartedEntry, err := client.RestartTimeEntry(ctx, selectedEntry.ID)
 	if err != nil {
-		logger.Fatalf("Failed to restart time entry: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to restart time entry", err: err}
 	}
 
-	fmt.Printf("Restarted time entry %d for project %s task %s\n",
+	fmt.Fprintf(w, "Restarted time entry %d for project %s task %s\n",
 		restartedEntry.ID, restartedEntry.Project.Name, restartedEntry.Task.Name)
+	return nil
 }
 
-func handleStatusDisplay(client *harvest.Client, userIDStr string, logger *log.Logger, sketchyBarMode bool, waybarMode bool) {
-	if userIDStr == "" {
-		logger.Fatalf("User ID must be provided")
-		os.Exit(1)
-	}
+// handleStatusDisplay prints the status line for today's entries.
+func handleStatusDisplay(ctx context.Context, w io.Writer, client harvest.API, userID int64, logger *log.Logger, sketchyBarMode bool, waybarMode bool) error {
+	// Get today's date for filtering
+	today := time.Now().Format("2006-01-02")
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	// List time entries for today filtered by current user
+	entries, err := client.ListTimeEntries(ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
 	if err != nil {
-		logger.Fatalf("Invalid user ID: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list time entries", err: err}
 	}
 
-	// Get today's date for filtering
-	today := time.Now().Format("2006-01-02")
+	fmt.Fprint(w, formatStatus(entries, sketchyBarMode, waybarMode))
+	return nil
+}
 
-	// List time entries for today filtered by current user
-	entries, err := client.ListTimeEntries(&today, &today, &userID)
+// handleToggle stops the running timer or, when none is running, restarts the
+// stopped entry from today that was updated most recently. It then prints the
+// same line as status so it can be bound to a single key.
+func handleToggle(ctx context.Context, w io.Writer, client harvest.API, userID int64, logger *log.Logger, sketchyBarMode bool, waybarMode bool) error {
+	today := time.Now().Format("2006-01-02")
+	entries, err := client.ListTimeEntries(ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
 	if err != nil {
-		logger.Fatalf("Failed to list time entries: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list time entries", err: err}
+	}
+
+	running, latest := -1, -1
+	for i, entry := range entries {
+		if entry.IsRunning {
+			running = i
+			break
+		}
+		if entryLockReason(entry) == "" && (latest < 0 || entry.UpdatedAt > entries[latest].UpdatedAt) {
+			latest = i
+		}
 	}
 
+	switch {
+	case running >= 0:
+		stopped, err := client.StopTimeEntry(ctx, entries[running].ID)
+		if err != nil {
+			return &apiError{action: "Failed to stop time entry", err: err}
+		}
+		logger.Printf("toggle: stopped time entry %d", stopped.ID)
+		entries[running] = *stopped
+	case latest >= 0:
+		restarted, err := client.RestartTimeEntry(ctx, entries[latest].ID)
+		if err != nil {
+			return &apiError{action: "Failed to restart time entry", err: err}
+		}
+		logger.Printf("toggle: restarted time entry %d", restarted.ID)
+		entries[latest] = *restarted
+	default:
+		fmt.Fprintln(os.Stderr, "No time entry from today to resume.")
+	}
+
+	fmt.Fprint(w, formatStatus(entries, sketchyBarMode, waybarMode))
+	return nil
+}
+
+// formatStatus renders the status line for today's entries: the running
+// timer and its first note word, or today's billable total when paused.
+func formatStatus(entries []harvest.TimeEntry, sketchyBarMode bool, waybarMode bool) string {
 	// Find running entries
 	var runningEntry *harvest.TimeEntry
 	for _, entry := range entries {
@@ -241,36 +302,21 @@ func handleStatusDisplay(client *harvest.Client, userIDStr string, logger *log.L
 		}
 
 		// Convert decimal hours to [HH:MM] format
-		hours := int(totalBillableHours)
-		minutes := int(math.Ceil((totalBillableHours - float64(hours)) * 60))
-
-		// Handle case where minutes rounds up to 60 (should increment hours)
-		if minutes >= 60 {
-			hours++
-			minutes = 0
-		}
+		hours, minutes := splitHours(totalBillableHours)
 
 		// Display total billable hours with [HH:MM] format
 		if sketchyBarMode {
-			fmt.Printf("[%02d:%02d] paused\n", hours, minutes)
-		} else if waybarMode {
-			fmt.Printf("{\"text\":\"<span color='#ff0000'>[%02d:%02d]</span> paused\",\"class\":\"paused\"}\n", hours, minutes)
-		} else {
-			fmt.Printf("#[fg=colour46][%02d:%02d]#[default] paused", hours, minutes)
+			return fmt.Sprintf("[%02d:%02d] paused\n", hours, minutes)
+		}
+		if waybarMode {
+			return fmt.Sprintf("{\"text\":\"<span color='#ff0000'>[%02d:%02d]</span> paused\",\"class\":\"paused\"}\n", hours, minutes)
 		}
-		return
+		return fmt.Sprintf("#[fg=colour46][%02d:%02d]#[default] paused", hours, minutes)
 	}
 
 	// Use total hours from the time entry (includes all accumulated time)
 	totalHours := runningEntry.Hours
-	hours := int(totalHours)
-	minutes := int(math.Ceil((totalHours - float64(hours)) * 60))
-
-	// Handle case where minutes rounds up to 60 (should increment hours)
-	if minutes >= 60 {
-		hours++
-		minutes = 0
-	}
+	hours, minutes := splitHours(totalHours)
 
 	// Prepare notes display (first word of first line only)
 	notesDisplay := ""
@@ -288,105 +334,181 @@ func handleStatusDisplay(client *harvest.Client, userIDStr string, logger *log.L
 
 	// Display running timer with [HH:MM] format
 	if sketchyBarMode {
-		fmt.Printf("[%02d:%02d]%s\n", hours, minutes, notesDisplay)
-	} else if waybarMode {
+		return fmt.Sprintf("[%02d:%02d]%s\n", hours, minutes, notesDisplay)
+	}
+	if waybarMode {
 		notesText := ""
 		if notesDisplay != "" {
 			notesText = fmt.Sprintf(" <span color='#ffffff'>%s</span>", notesDisplay[1:]) // Remove leading space
 		}
-		fmt.Printf("{\"text\":\"<span color='#00ff00'>[%02d:%02d]</span>%s\",\"class\":\"running\"}\n", hours, minutes, notesText)
-	} else {
-		fmt.Printf("#[fg=colour46][%02d:%02d]#[default]%s",
-			hours, minutes, notesDisplay)
+		return fmt.Sprintf("{\"text\":\"<span color='#00ff00'>[%02d:%02d]</span>%s\",\"class\":\"running\"}\n", hours, minutes, notesText)
 	}
+	return fmt.Sprintf("#[fg=colour46][%02d:%02d]#[default]%s",
+		hours, minutes, notesDisplay)
 }
 
-func handleAddTime(client *harvest.Client, userIDStr string, logger *log.Logger, minutesToAdd int) {
-	if userIDStr == "" {
-		logger.Fatalf("User ID must be provided")
-		os.Exit(1)
-	}
+// timeAdjustment describes a change to an entry's hours made by the add
+// command. Either delta is added or, when set is non-nil, the hours are
+// replaced. The target is entryID, the entry picked from date's list, or the
+// running timer.
+type timeAdjustment struct {
+	delta   float64
+	set     *float64
+	entryID int64
+	pick    bool
+	date    string
+}
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
-	if err != nil {
-		logger.Fatalf("Invalid user ID: %v", err)
-		os.Exit(1)
-	}
+// handleAddTime applies adj to its target entry and reports the new total.
+func handleAddTime(ctx context.Context, w io.Writer, client harvest.API, userID int64, logger *log.Logger, adj timeAdjustment) error {
+	var target *harvest.TimeEntry
+	switch {
+	case adj.entryID != 0:
+		entry, err := client.GetTimeEntry(ctx, adj.entryID)
+		if err != nil {
+			return &apiError{action: "Failed to fetch time entry", err: err}
+		}
+		target = entry
+	case adj.pick:
+		entries, err := client.ListTimeEntries(ctx, harvest.TimeEntryFilter{From: &adj.date, To: &adj.date, UserID: &userID})
+		if err != nil {
+			return &apiError{action: "Failed to list time entries", err: err}
+		}
+		if len(entries) == 0 {
+			fmt.Fprintf(w, "No time entries found for %s.\n", adj.date)
+			return nil
+		}
+		entryOptions := make([]string, len(entries))
+		for i, entry := range entries {
+			entryOptions[i] = formatEntryOption(entry)
+		}
+		idx, err := prompt.SelectPrompt(entryOptions, "Select a time entry to adjust:")
+		if err != nil {
+			return fmt.Errorf("selection error: %w", err)
+		}
+		target = &entries[idx]
+	default:
+		// Get today's date for filtering
+		today := time.Now().Format("2006-01-02")
 
-	// Get today's date for filtering
-	today := time.Now().Format("2006-01-02")
+		// List time entries for today filtered by current user
+		entries, err := client.ListTimeEntries(ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
+		if err != nil {
+			return &apiError{action: "Failed to list time entries", err: err}
+		}
 
-	// List time entries for today filtered by current user
-	entries, err := client.ListTimeEntries(&today, &today, &userID)
-	if err != nil {
-		logger.Fatalf("Failed to list time entries: %v", err)
-		os.Exit(1)
-	}
+		// Find running entries
+		for _, entry := range entries {
+			if entry.IsRunning {
+				target = &entry
+				break // Take the first running entry (there should typically be only one)
+			}
+		}
 
-	// Find running entries
-	var runningEntry *harvest.TimeEntry
-	for _, entry := range entries {
-		if entry.IsRunning {
-			runningEntry = &entry
-			break // Take the first running entry (there should typically be only one)
+		if target == nil {
+			fmt.Fprintln(w, "No running timer found to add time to.")
+			return nil
 		}
 	}
 
-	if runningEntry == nil {
-		fmt.Println("No running timer found to add time to.")
-		return
+	if reason := entryLockReason(*target); reason != "" {
+		return &cliError{code: exitLocked, msg: fmt.Sprintf("Time entry %d can't be changed: %s.", target.ID, reason)}
 	}
 
 	// Calculate new total hours
-	additionalHours := float64(minutesToAdd) / 60.0
-	newTotalHours := runningEntry.Hours + additionalHours
+	newTotalHours := target.Hours + adj.delta
+	if adj.set != nil {
+		newTotalHours = *adj.set
+	}
+	if newTotalHours < 0 {
+		hours, minutes := splitHours(target.Hours)
+		return &cliError{code: exitError, msg: fmt.Sprintf("Time entry %d only has [%02d:%02d]; it can't go below zero.", target.ID, hours, minutes)}
+	}
 
-	// Update the time entry with new hours
-	updatedEntry, err := client.UpdateTimeEntry(runningEntry.ID, newTotalHours)
+	// Update the time entry with new hours, or new times on accounts that
+	// track them
+	company, err := client.Company(ctx)
+	if err != nil {
+		return &apiError{action: "Failed to fetch account settings", err: err}
+	}
+	update := harvest.TimeEntryUpdateRequest{Hours: &newTotalHours}
+	if company.WantsTimestampTimers {
+		if update, err = endTimeUpdate(*target, newTotalHours, company); err != nil {
+			return err
+		}
+	}
+	updatedEntry, err := client.UpdateTimeEntry(ctx, target.ID, update)
 	if err != nil {
-		logger.Fatalf("Failed to update time entry: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to update time entry", err: err}
 	}
 
 	// Convert new total to [HH:MM] format for display
-	totalHours := updatedEntry.Hours
-	hours := int(totalHours)
-	minutes := int(math.Ceil((totalHours - float64(hours)) * 60))
-
-	// Handle case where minutes rounds up to 60 (should increment hours)
-	if minutes >= 60 {
-		hours++
-		minutes = 0
+	hours, minutes := splitHours(updatedEntry.Hours)
+
+	what := "time entry"
+	if updatedEntry.IsRunning {
+		what = "running timer"
+	}
+	deltaMinutes := int(math.Round(adj.delta * 60))
+	switch {
+	case adj.set != nil:
+		fmt.Fprintf(w, "Set %s to [%02d:%02d] for project %s task %s\n",
+			what, hours, minutes, updatedEntry.Project.Name, updatedEntry.Task.Name)
+	case deltaMinutes < 0:
+		fmt.Fprintf(w, "Subtracted %d minutes from %s. New total: [%02d:%02d] for project %s task %s\n",
+			-deltaMinutes, what, hours, minutes, updatedEntry.Project.Name, updatedEntry.Task.Name)
+	default:
+		fmt.Fprintf(w, "Added %d minutes to %s. New total: [%02d:%02d] for project %s task %s\n",
+			deltaMinutes, what, hours, minutes, updatedEntry.Project.Name, updatedEntry.Task.Name)
 	}
+	return nil
+}
 
-	fmt.Printf("Added %d minutes to running timer. New total: [%02d:%02d] for project %s task %s\n",
-		minutesToAdd, hours, minutes, updatedEntry.Project.Name, updatedEntry.Task.Name)
+// endTimeUpdate moves the end of a stopped entry so that it lasts hours, for
+// accounts that track start and end times rather than hours. Running timers
+// can't be changed that way.
+func endTimeUpdate(entry harvest.TimeEntry, hours float64, company *harvest.Company) (harvest.TimeEntryUpdateRequest, error) {
+	if entry.IsRunning {
+		return harvest.TimeEntryUpdateRequest{}, &cliError{code: exitError, msg: "This account tracks start and end times, so time can't be added to a running timer. Stop it and correct its times with 'harvest_cli edit'."}
+	}
+	if entry.StartedTime == nil || *entry.StartedTime == "" {
+		return harvest.TimeEntryUpdateRequest{}, &cliError{code: exitError, msg: fmt.Sprintf("Time entry %d has no start time. Set its times with 'harvest_cli edit'.", entry.ID)}
+	}
+	start, err := timeparse.ParseClock(*entry.StartedTime)
+	if err != nil {
+		return harvest.TimeEntryUpdateRequest{}, &cliError{code: exitError, msg: fmt.Sprintf("Time entry %d: %v. Set its times with 'harvest_cli edit'.", entry.ID, err)}
+	}
+	end := start + time.Duration(hours*float64(time.Hour)).Round(time.Minute)
+	if end >= 24*time.Hour {
+		return harvest.TimeEntryUpdateRequest{}, &cliError{code: exitError, msg: fmt.Sprintf("Time entry %d would end after midnight. Set its times with 'harvest_cli edit'.", entry.ID)}
+	}
+	startedTime := timeparse.FormatClock(start, company.ClockFormat)
+	endedTime := timeparse.FormatClock(end, company.ClockFormat)
+	return harvest.TimeEntryUpdateRequest{StartedTime: &startedTime, EndedTime: &endedTime}, nil
 }
 
-func handleInvoiceList(client *harvest.Client, logger *log.Logger, from, to *string, jsonOutput bool) {
-	invoices, err := client.ListInvoices(from, to)
+func handleInvoiceList(ctx context.Context, w io.Writer, client harvest.API, from, to *string, jsonOutput bool) error {
+	invoices, err := client.ListInvoices(ctx, from, to)
 	if err != nil {
-		logger.Fatalf("Failed to list invoices: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list invoices", err: err}
 	}
 
 	if jsonOutput {
 		out, err := jsonMarshal(invoices)
 		if err != nil {
-			logger.Fatalf("Failed to marshal invoices: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to marshal invoices: %w", err)
 		}
-		fmt.Println(string(out))
-		return
+		fmt.Fprintln(w, string(out))
+		return nil
 	}
 
 	if len(invoices) == 0 {
-		fmt.Println("No invoices found.")
-		return
+		fmt.Fprintln(w, "No invoices found.")
+		return nil
 	}
 
-	fmt.Printf("%-12s %-12s %12s %-10s %s\n", "DATE", "NUMBER", "AMOUNT", "STATUS", "CLIENT")
-	fmt.Println(strings.Repeat("-", 80))
+	fmt.Fprintf(w, "%-12s %-12s %12s %-10s %s\n", "DATE", "NUMBER", "AMOUNT", "STATUS", "CLIENT")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
 	for _, inv := range invoices {
 		issuedDate := inv.IssuedAt
 		if issuedDate == "" {
@@ -400,35 +522,154 @@ func handleInvoiceList(client *harvest.Client, logger *log.Logger, from, to *str
 		if len(clientName) > 30 {
 			clientName = clientName[:27] + "..."
 		}
-		fmt.Printf("%-12s %-12s %12s %-10s %s\n",
+		fmt.Fprintf(w, "%-12s %-12s %12s %-10s %s\n",
 			issuedDate, inv.Number, amount, inv.Status, clientName)
 	}
+	return nil
+}
+
+// entryQuery selects time entries for the entries command. Filter is sent to
+// Harvest; the name matches and Billable are applied afterwards since the
+// API only filters on IDs and has no billable filter.
+type entryQuery struct {
+	Filter   harvest.TimeEntryFilter
+	Project  string
+	Client   string
+	Task     string
+	Billable *bool
+}
+
+func (q entryQuery) match(entry harvest.TimeEntry) bool {
+	contains := func(name, needle string) bool {
+		return needle == "" || strings.Contains(strings.ToLower(name), strings.ToLower(needle))
+	}
+	if q.Billable != nil && entry.Billable != *q.Billable {
+		return false
+	}
+	return contains(entry.Project.Name, q.Project) &&
+		contains(entry.Client.Name, q.Client) &&
+		contains(entry.Task.Name, q.Task)
+}
+
+func handleEntryList(ctx context.Context, w io.Writer, client harvest.API, query entryQuery, jsonOutput, csvOutput bool) error {
+	all, err := client.ListTimeEntries(ctx, query.Filter)
+	if err != nil {
+		return &apiError{action: "Failed to list time entries", err: err}
+	}
+	entries := make([]harvest.TimeEntry, 0, len(all))
+	for _, entry := range all {
+		if query.match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if jsonOutput {
+		out, err := jsonMarshal(entries)
+		if err != nil {
+			return fmt.Errorf("failed to marshal time entries: %w", err)
+		}
+		fmt.Fprintln(w, string(out))
+		return nil
+	}
+
+	if csvOutput {
+		if err := writeEntriesCSV(w, entries); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No time entries found.")
+		return nil
+	}
+
+	var total float64
+	fmt.Fprintf(w, "%-12s %-25s %-20s %7s %s\n", "DATE", "PROJECT", "TASK", "HOURS", "NOTES")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	for _, entry := range entries {
+		total += entry.Hours
+		projectName := entry.Project.Name
+		if len(projectName) > 23 {
+			projectName = projectName[:20] + "..."
+		}
+		taskName := entry.Task.Name
+		if len(taskName) > 18 {
+			taskName = taskName[:15] + "..."
+		}
+		hours, minutes := splitHours(entry.Hours)
+		duration := fmt.Sprintf("%d:%02d", hours, minutes)
+		if entry.IsRunning {
+			duration += "*"
+		}
+		notes := ""
+		if entry.Notes != nil {
+			notes = strings.ReplaceAll(*entry.Notes, "\n", " | ")
+			if len(notes) > 30 {
+				notes = notes[:27] + "..."
+			}
+		}
+		fmt.Fprintf(w, "%-12s %-25s %-20s %7s %s\n",
+			entry.SpentDate, projectName, taskName, duration, notes)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	hours, minutes := splitHours(total)
+	count := fmt.Sprintf("TOTAL (%d entries)", len(entries))
+	if len(entries) == 1 {
+		count = "TOTAL (1 entry)"
+	}
+	fmt.Fprintf(w, "%-59s %7s\n", count, fmt.Sprintf("%d:%02d", hours, minutes))
+	return nil
 }
 
-func handleExpenseList(client *harvest.Client, logger *log.Logger, from, to *string, jsonOutput bool) {
-	expenses, err := client.ListExpenses(from, to)
+// writeEntriesCSV writes time entries as CSV with decimal hours.
+func writeEntriesCSV(out io.Writer, entries []harvest.TimeEntry) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"ID", "Date", "Client", "Project", "Task", "Hours", "Billable", "Running", "Billed", "Notes"})
+	for _, entry := range entries {
+		notes := ""
+		if entry.Notes != nil {
+			notes = *entry.Notes
+		}
+		w.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.SpentDate,
+			entry.Client.Name,
+			entry.Project.Name,
+			entry.Task.Name,
+			strconv.FormatFloat(entry.Hours, 'f', 2, 64),
+			strconv.FormatBool(entry.Billable),
+			strconv.FormatBool(entry.IsRunning),
+			strconv.FormatBool(entry.IsBilled),
+			notes,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func handleExpenseList(ctx context.Context, w io.Writer, client harvest.API, from, to *string, jsonOutput bool) error {
+	expenses, err := client.ListExpenses(ctx, from, to)
 	if err != nil {
-		logger.Fatalf("Failed to list expenses: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list expenses", err: err}
 	}
 
 	if jsonOutput {
 		out, err := jsonMarshal(expenses)
 		if err != nil {
-			logger.Fatalf("Failed to marshal expenses: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to marshal expenses: %w", err)
 		}
-		fmt.Println(string(out))
-		return
+		fmt.Fprintln(w, string(out))
+		return nil
 	}
 
 	if len(expenses) == 0 {
-		fmt.Println("No expenses found.")
-		return
+		fmt.Fprintln(w, "No expenses found.")
+		return nil
 	}
 
-	fmt.Printf("%-12s %-25s %-16s %10s %s\n", "DATE", "PROJECT", "CATEGORY", "AMOUNT", "NOTES")
-	fmt.Println(strings.Repeat("-", 80))
+	fmt.Fprintf(w, "%-12s %-25s %-16s %10s %s\n", "DATE", "PROJECT", "CATEGORY", "AMOUNT", "NOTES")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
 	for _, exp := range expenses {
 		spentDate := exp.SpentDate
 		if len(spentDate) > 10 {
@@ -450,12 +691,13 @@ func handleExpenseList(client *harvest.Client, logger *log.Logger, from, to *str
 				notes = notes[:27] + "..."
 			}
 		}
-		fmt.Printf("%-12s %-25s %-16s %10s %s\n",
+		fmt.Fprintf(w, "%-12s %-25s %-16s %10s %s\n",
 			spentDate, projectName, categoryName, amount, notes)
 	}
+	return nil
 }
 
-func handleExpenseCreate(client *harvest.Client, userIDStr string, logger *log.Logger, projectIDStr, categoryIDStr, amountStr, dateStr, notes, receiptPath string) {
+func handleExpenseCreate(ctx context.Context, w io.Writer, client harvest.API, projectIDStr, categoryIDStr, amountStr, dateStr, notes, receiptPath string) error {
 	if dateStr == "" {
 		dateStr = time.Now().Format("2006-01-02")
 	}
@@ -464,18 +706,15 @@ func handleExpenseCreate(client *harvest.Client, userIDStr string, logger *log.L
 	if projectIDStr != "" && categoryIDStr != "" && amountStr != "" {
 		projectID, err := strconv.ParseInt(projectIDStr, 10, 64)
 		if err != nil {
-			logger.Fatalf("Invalid project ID: %v", err)
-			os.Exit(1)
+			return &cliError{code: exitError, msg: fmt.Sprintf("Invalid project ID: %v", err)}
 		}
 		categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
 		if err != nil {
-			logger.Fatalf("Invalid category ID: %v", err)
-			os.Exit(1)
+			return &cliError{code: exitError, msg: fmt.Sprintf("Invalid category ID: %v", err)}
 		}
 		amount, err := strconv.ParseFloat(amountStr, 64)
 		if err != nil {
-			logger.Fatalf("Invalid amount: %v", err)
-			os.Exit(1)
+			return &cliError{code: exitError, msg: fmt.Sprintf("Invalid amount: %v", err)}
 		}
 
 		req := harvest.ExpenseCreateRequest{
@@ -487,57 +726,34 @@ func handleExpenseCreate(client *harvest.Client, userIDStr string, logger *log.L
 		if notes != "" {
 			req.Notes = &notes
 		}
-
-		if receiptPath != "" {
-			if _, err := os.Stat(receiptPath); os.IsNotExist(err) {
-				logger.Fatalf("Receipt file not found: %s", receiptPath)
-				os.Exit(1)
-			}
-			exp, err := client.CreateExpenseWithReceipt(req, receiptPath)
-			if err != nil {
-				logger.Fatalf("Failed to create expense: %v", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Created expense #%d: $%.2f - %s (%s) [%s] (receipt: %s)\n",
-				exp.ID, exp.TotalCost, exp.ExpenseCategory.Name, exp.Project.Name, exp.SpentDate, filepath.Base(receiptPath))
-		} else {
-			exp, err := client.CreateExpense(req)
-			if err != nil {
-				logger.Fatalf("Failed to create expense: %v", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Created expense #%d: $%.2f - %s (%s) [%s]\n",
-				exp.ID, exp.TotalCost, exp.ExpenseCategory.Name, exp.Project.Name, exp.SpentDate)
-		}
-		return
+		return submitExpense(ctx, w, client, req, receiptPath)
 	}
 
 	// Interactive mode: select project
-	projects, err := client.ListProjects()
+	assignments, err := client.ListMyProjectAssignments(ctx)
 	if err != nil {
-		logger.Fatalf("Failed to list projects: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list project assignments", err: err}
 	}
-	projectOptions := make([]string, len(projects))
-	for i, p := range projects {
-		projectOptions[i] = fmt.Sprintf("%s \033[36m(%s)\033[0m", p.Name, p.Client.Name)
+	if len(assignments) == 0 {
+		return &cliError{code: exitError, msg: "No project assignments found"}
+	}
+	projectOptions := make([]string, len(assignments))
+	for i, pa := range assignments {
+		projectOptions[i] = fmt.Sprintf("%s \033[36m(%s)\033[0m", pa.Project.Name, pa.Client.Name)
 	}
 	idx, err := prompt.SelectPrompt(projectOptions, "Select a project:")
 	if err != nil {
-		logger.Fatalf("prompt error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("prompt error: %w", err)
 	}
-	selectedProject := projects[idx]
+	selectedProject := assignments[idx].Project
 
 	// Interactive mode: select expense category
-	categories, err := client.ListExpenseCategories()
+	categories, err := client.ListExpenseCategories(ctx)
 	if err != nil {
-		logger.Fatalf("Failed to list expense categories: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to list expense categories", err: err}
 	}
 	if len(categories) == 0 {
-		logger.Fatalf("No expense categories found")
-		os.Exit(1)
+		return &cliError{code: exitError, msg: "No expense categories found"}
 	}
 	categoryOptions := make([]string, len(categories))
 	for i, c := range categories {
@@ -545,29 +761,25 @@ func handleExpenseCreate(client *harvest.Client, userIDStr string, logger *log.L
 	}
 	idx, err = prompt.SelectPrompt(categoryOptions, "Select an expense category:")
 	if err != nil {
-		logger.Fatalf("prompt error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("prompt error: %w", err)
 	}
 	selectedCategory := categories[idx]
 
 	// Interactive mode: prompt for amount
 	amountStr, err = prompt.InputPrompt("Amount:", "")
 	if err != nil {
-		logger.Fatalf("prompt error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("prompt error: %w", err)
 	}
 	amount, err := strconv.ParseFloat(amountStr, 64)
 	if err != nil {
-		logger.Fatalf("Invalid amount: %v", err)
-		os.Exit(1)
+		return &cliError{code: exitError, msg: fmt.Sprintf("Invalid amount: %v", err)}
 	}
 
 	// Interactive mode: prompt for notes
 	var expenseNotes string
 	expenseNotes, err = prompt.InputPrompt("Notes (optional):", notes)
 	if err != nil {
-		logger.Fatalf("prompt error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("prompt error: %w", err)
 	}
 
 	req := harvest.ExpenseCreateRequest{
@@ -579,320 +791,321 @@ func handleExpenseCreate(client *harvest.Client, userIDStr string, logger *log.L
 	if expenseNotes != "" {
 		req.Notes = &expenseNotes
 	}
+	return submitExpense(ctx, w, client, req, receiptPath)
+}
 
-	if receiptPath != "" {
-		if _, err := os.Stat(receiptPath); os.IsNotExist(err) {
-			logger.Fatalf("Receipt file not found: %s", receiptPath)
-			os.Exit(1)
-		}
-		exp, err := client.CreateExpenseWithReceipt(req, receiptPath)
+// submitExpense creates the expense, uploading receiptPath with it when set.
+func submitExpense(ctx context.Context, w io.Writer, client harvest.API, req harvest.ExpenseCreateRequest, receiptPath string) error {
+	if receiptPath == "" {
+		exp, err := client.CreateExpense(ctx, req)
 		if err != nil {
-			logger.Fatalf("Failed to create expense: %v", err)
-			os.Exit(1)
+			return &apiError{action: "Failed to create expense", err: err}
 		}
-		fmt.Printf("Created expense #%d: $%.2f - %s (%s) [%s] (receipt: %s)\n",
-			exp.ID, exp.TotalCost, exp.ExpenseCategory.Name, exp.Project.Name, exp.SpentDate, filepath.Base(receiptPath))
-	} else {
-		exp, err := client.CreateExpense(req)
-		if err != nil {
-			logger.Fatalf("Failed to create expense: %v", err)
-			os.Exit(1)
-		}
-		fmt.Printf("Created expense #%d: $%.2f - %s (%s) [%s]\n",
+		fmt.Fprintf(w, "Created expense #%d: $%.2f - %s (%s) [%s]\n",
 			exp.ID, exp.TotalCost, exp.ExpenseCategory.Name, exp.Project.Name, exp.SpentDate)
+		return nil
+	}
+	if _, err := os.Stat(receiptPath); os.IsNotExist(err) {
+		return &cliError{code: exitError, msg: fmt.Sprintf("Receipt file not found: %s", receiptPath)}
+	}
+	exp, err := client.CreateExpenseWithReceipt(ctx, req, receiptPath)
+	if err != nil {
+		return &apiError{action: "Failed to create expense", err: err}
 	}
+	fmt.Fprintf(w, "Created expense #%d: $%.2f - %s (%s) [%s] (receipt: %s)\n",
+		exp.ID, exp.TotalCost, exp.ExpenseCategory.Name, exp.Project.Name, exp.SpentDate, filepath.Base(receiptPath))
+	return nil
 }
 
-func jsonMarshal(v interface{}) ([]byte, error) {
-	return json.MarshalIndent(v, "", "  ")
+// formatEntryOption renders a time entry for the entry pickers as project,
+// task, status, [HH:MM] and the first line of notes.
+func formatEntryOption(entry harvest.TimeEntry) string {
+	status := "\033[33mStopped\033[0m" // Yellow for stopped
+	if entry.IsRunning {
+		status = "\033[32mRunning\033[0m" // Green for running
+	}
+	notes := ""
+	if entry.Notes != nil {
+		// Replace newlines with spaces and clean up formatting
+		notes = strings.ReplaceAll(*entry.Notes, "\n", " | ")
+		notes = strings.ReplaceAll(notes, "\r", " | ")
+		// Trim whitespace and add padding
+		notes = strings.TrimSpace(notes)
+		// Truncate very long notes to prevent wrapping issues
+		if len(notes) > 60 {
+			notes = notes[:57] + "..."
+		}
+		if notes != "" {
+			// Add cyan color highlighting for notes
+			notes = fmt.Sprintf("  \033[36m%s\033[0m", notes)
+		}
+	}
+
+	// Convert decimal hours to [HH:MM] format
+	hours, minutes := splitHours(entry.Hours)
+
+	return fmt.Sprintf("%s - %s (%s) [%02d:%02d]%s",
+		entry.Project.Name, entry.Task.Name, status, hours, minutes, notes)
 }
 
-func main() {
-	// Setup logger
-	logger, err := config.SetupLogger()
-	if err != nil {
-		log.Fatalf("Failed to setup logger: %v", err)
+// entryLockReason explains why Harvest won't let the entry be changed, or
+// returns "" when it can be edited.
+func entryLockReason(entry harvest.TimeEntry) string {
+	switch {
+	case entry.IsLocked:
+		if entry.LockedReason != nil && *entry.LockedReason != "" {
+			return *entry.LockedReason
+		}
+		return "the entry is locked"
+	case entry.IsClosed:
+		return "the entry has been approved"
+	case entry.IsBilled:
+		return "the entry has been invoiced"
 	}
+	return ""
+}
 
-	var note string
-	var configPath string
-	var ignoreConfig bool
-	var selectEntry bool
-	var showStatus bool
-	var sketchyBarMode bool
-	var waybarMode bool
-	var stopTimer bool
-	var addMinutes int
-	var lazyProjectSelect bool
-	var listInvoices bool
-	var listExpenses bool
-	var fromDate string
-	var toDate string
-	var jsonOutput bool
-	var createExpense bool
-	var expenseProjectID string
-	var expenseCategoryID string
-	var expenseAmount string
-	var expenseDate string
-	flag.StringVar(&note, "n", "", "Initial notes text")
-	flag.StringVar(&configPath, "c", config.DefaultConfigPath(), "Config file path")
-	flag.BoolVar(&ignoreConfig, "i", false, "Ignore loading local configuration")
-	flag.BoolVar(&selectEntry, "e", false, "Select and restart an existing time entry")
-	flag.BoolVar(&showStatus, "s", false, "Show current running timer status")
-	flag.BoolVar(&sketchyBarMode, "b", false, "Format output for SketchyBar (plain text, must be used with -s)")
-	flag.BoolVar(&waybarMode, "w", false, "Format output for Waybar (JSON format, must be used with -s)")
-	flag.BoolVar(&stopTimer, "q", false, "Stop the currently running timer")
-	flag.IntVar(&addMinutes, "a", 0, "Add minutes to current running timer")
-	flag.BoolVar(&lazyProjectSelect, "l", false, "Lazy project selection (hide list until typing)")
-	flag.BoolVar(&listInvoices, "I", false, "List recent invoices")
-	flag.BoolVar(&listExpenses, "E", false, "List expenses")
-	flag.StringVar(&fromDate, "from", "", "From date (YYYY-MM-DD)")
-	flag.StringVar(&toDate, "to", "", "To date (YYYY-MM-DD)")
-	flag.BoolVar(&jsonOutput, "json", false, "Output as raw JSON")
-	flag.BoolVar(&createExpense, "create", false, "Create a new expense (must be used with -E)")
-	flag.StringVar(&expenseProjectID, "project-id", "", "Project ID for expense")
-	flag.StringVar(&expenseCategoryID, "category-id", "", "Expense category ID")
-	flag.StringVar(&expenseAmount, "amount", "", "Expense total cost")
-	flag.StringVar(&expenseDate, "date", "", "Expense date (YYYY-MM-DD, default: today)")
-	var receiptPath string
-	flag.StringVar(&receiptPath, "receipt", "", "Path to receipt file (PDF/image)")
-	var ticket string
-	flag.StringVar(&ticket, "t", "", "External ticket number to prefix notes")
-	flag.Parse()
-
-	// Validate flags
-	if sketchyBarMode && !showStatus {
-		logger.Fatalf("-b flag must be used with -s flag")
-		os.Exit(1)
-	}
-	if waybarMode && !showStatus {
-		logger.Fatalf("-w flag must be used with -s flag")
-		os.Exit(1)
-	}
-	if sketchyBarMode && waybarMode {
-		logger.Fatalf("-b and -w flags cannot be used together")
-		os.Exit(1)
-	}
-
-	// Validate add minutes flag
-	if addMinutes < 0 {
-		logger.Fatalf("-a flag must be a positive number of minutes")
-		os.Exit(1)
-	}
-
-	// Check for conflicting flags with -a
-	if addMinutes > 0 && (selectEntry || showStatus || stopTimer) {
-		logger.Fatalf("-a flag cannot be used with -e, -s, or -q flags")
-		os.Exit(1)
-	}
-
-	// Validate --create flag
-	if createExpense && !listExpenses {
-		logger.Fatalf("--create flag must be used with -E flag")
-		os.Exit(1)
-	}
-
-	// Load global configuration
-	globalCfg, err := config.LoadGlobal()
-	if err != nil {
-		logger.Fatalf("Failed to load global config: %v", err)
-		os.Exit(1)
-	}
-
-	// Check if global config is complete, if not, prompt for setup
-	if globalCfg.HarvestAccountID == "" || globalCfg.HarvestAccessToken == "" || globalCfg.HarvestUserID == "" {
-		setupErr := setupGlobalConfig(globalCfg)
-		if setupErr != nil {
-			logger.Fatalf("Failed to setup global config: %v", setupErr)
-			os.Exit(1)
-		}
-	}
-
-	// Combine ticket with note if provided
-	if ticket != "" {
-		// Ensure ticket starts with '#'
-		if !strings.HasPrefix(ticket, "#") {
-			ticket = "#" + ticket
-		}
-		prefix := fmt.Sprintf("%s\n", ticket)
-		if note != "" {
-			note = prefix + note
-		} else {
-			note = prefix
-		}
-	}
-
-	var cfg *config.Config
-	var loadErr error
-	if !ignoreConfig {
-		cfg, loadErr = config.Load(configPath)
-	} else {
-		cfg = &config.Config{}
-	}
+// splitHours converts decimal hours to whole hours and minutes, rounding
+// minutes up as Harvest's timer display does.
+func splitHours(totalHours float64) (int, int) {
+	// The tolerance stops float error in values such as 1.1 from rounding
+	// up a whole extra minute.
+	total := int(math.Ceil(totalHours*60 - 1e-9))
+	return total / 60, total % 60
+}
 
-	if loadErr != nil {
-		logger.Fatalf("Failed to load config: %v", loadErr)
-		os.Exit(1)
-	}
+func jsonMarshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
 
-	client, clientErr := harvest.NewClient(globalCfg.HarvestAccountID, globalCfg.HarvestAccessToken)
-	if clientErr != nil {
-		logger.Fatalf("Auth error: %v", clientErr)
-		os.Exit(1)
-	}
+// startOptions are the flags of the start command.
+type startOptions struct {
+	note   string
+	ticket string
+	lazy   bool
+	// date is the day to log against; the zero value means today.
+	date time.Time
+	// span, when set, creates a completed entry instead of a running timer.
+	span *timeparse.Span
+}
 
-	// Handle stop timer mode
-	if stopTimer {
-		handleStopTimer(client, globalCfg.HarvestUserID, logger)
-		return
+// handleStart creates a time entry for the directory's project and task,
+// prompting for any that aren't configured, and saves them as defaults.
+func handleStart(a *app, w io.Writer, opts startOptions) error {
+	// Projects selection
+	assignments, err := a.client.ListMyProjectAssignments(a.ctx)
+	if err != nil {
+		return &apiError{action: "Failed to list project assignments", err: err}
 	}
-
-	// Handle time entry selection mode
-	if selectEntry {
-		handleTimeEntrySelection(client, globalCfg.HarvestUserID, logger)
-		return
+	if len(assignments) == 0 {
+		fmt.Fprintln(w, "You are not assigned to any active projects.")
+		return nil
 	}
-
-	// Handle status display mode
-	if showStatus {
-		handleStatusDisplay(client, globalCfg.HarvestUserID, logger, sketchyBarMode, waybarMode)
-		return
+	selected, err := selectAssignment(a, assignments, a.resolved.ProjectID, opts.lazy)
+	if err != nil {
+		return err
 	}
+	selectedProjectID := selected.Project.ID
 
-	// Handle add time mode
-	if addMinutes > 0 {
-		handleAddTime(client, globalCfg.HarvestUserID, logger, addMinutes)
-		return
+	// Tasks selection
+	task, ok, err := selectTask(a, selected, a.resolved.TaskID)
+	if err != nil || !ok {
+		return err
 	}
+	selectedTaskID := task.ID
 
-	// Handle invoice listing
-	if listInvoices {
-		var from, to *string
-		if fromDate != "" {
-			from = &fromDate
-		}
-		if toDate != "" {
-			to = &toDate
-		}
-		handleInvoiceList(client, logger, from, to, jsonOutput)
-		return
+	// Notes input
+	notes, err := promptNotes(a, opts)
+	if err != nil {
+		return err
 	}
 
-	// Handle expense listing / creation
-	if listExpenses {
-		if createExpense {
-			handleExpenseCreate(client, globalCfg.HarvestUserID, logger, expenseProjectID, expenseCategoryID, expenseAmount, expenseDate, note, receiptPath)
-			return
-		}
-		var from, to *string
-		if fromDate != "" {
-			from = &fromDate
+	// Create time entry. Harvest only runs timers for today, so other days
+	// get a stopped entry with no hours yet.
+	now := time.Now()
+	date := timeparse.Day(now)
+	if !opts.date.IsZero() {
+		date = opts.date
+	}
+	req := harvest.TimeEntryRequest{ProjectID: selectedProjectID, TaskID: selectedTaskID, SpendDate: date.Format(timeparse.DateLayout), Notes: notes}
+	running := opts.span == nil && date.Equal(timeparse.Day(now))
+	if !running {
+		company, err := a.client.Company(a.ctx)
+		if err != nil {
+			return &apiError{action: "Failed to fetch account settings", err: err}
 		}
-		if toDate != "" {
-			to = &toDate
+		if err := setEntryTiming(&req, company, opts.span, date, now); err != nil {
+			return &cliError{code: exitError, msg: err.Error()}
 		}
-		handleExpenseList(client, logger, from, to, jsonOutput)
-		return
 	}
-
-	// Projects selection
-	projects, err := client.ListProjects()
+	resp, err := a.client.CreateTimeEntry(a.ctx, req)
 	if err != nil {
-		logger.Fatalf("Failed to list projects: %v", err)
-		os.Exit(1)
+		return &apiError{action: "Failed to create time entry", err: err}
+	}
+
+	switch {
+	case running:
+		fmt.Fprintf(w, "Created time entry ID %d for project %s task %s\n", resp.ID, resp.Project.Name, resp.Task.Name)
+	case opts.span == nil:
+		fmt.Fprintf(w, "Created stopped time entry ID %d on %s for project %s task %s\n", resp.ID, resp.SpentDate, resp.Project.Name, resp.Task.Name)
+	default:
+		hours, minutes := splitHours(opts.span.Hours)
+		fmt.Fprintf(w, "Logged %d:%02d as time entry ID %d on %s for project %s task %s\n", hours, minutes, resp.ID, resp.SpentDate, resp.Project.Name, resp.Task.Name)
 	}
-	projectOptions := make([]string, len(projects))
-	for i, p := range projects {
-		projectOptions[i] = fmt.Sprintf("%s \033[36m(%s)\033[0m", p.Name, p.Client.Name)
+
+	// Save defaults, unless they came from a branch rule
+	if rule := a.resolved.BranchRule; rule == nil || rule.ProjectID == 0 && rule.TaskID == 0 {
+		a.cfg.ProjectID = selectedProjectID
+		a.cfg.TaskID = selectedTaskID
+		a.saveLocalConfig()
 	}
+	return nil
+}
 
-	var selectedProjectID int64
-	if cfg.ProjectID != 0 {
+// selectAssignment returns the assignment for preferredID, or prompts for
+// one when it is zero or no longer assigned.
+func selectAssignment(a *app, assignments []harvest.ProjectAssignment, preferredID int64, lazy bool) (*harvest.ProjectAssignment, error) {
+	if preferredID != 0 {
 		// verify exists in list
-		found := false
-		for _, p := range projects {
-			if p.ID == cfg.ProjectID {
-				found = true
-				break
+		for i := range assignments {
+			if assignments[i].Project.ID == preferredID {
+				return &assignments[i], nil
 			}
 		}
-		if found {
-			selectedProjectID = cfg.ProjectID
-		}
 	}
-	if selectedProjectID == 0 {
-		var err error
-		idx, err := prompt.SelectPromptWithOptions(projectOptions, "Select a project:", lazyProjectSelect)
-		if err != nil {
-			logger.Fatalf("prompt error: %v", err)
-			os.Exit(1)
-		}
-		selectedProjectID = projects[idx].ID
+	projectOptions := make([]string, len(assignments))
+	for i, pa := range assignments {
+		projectOptions[i] = fmt.Sprintf("%s \033[36m(%s)\033[0m", pa.Project.Name, pa.Client.Name)
 	}
-
-	// Tasks selection
-	tasks, err := client.ListTasks(selectedProjectID)
+	idx, err := prompt.SelectPromptWithOptions(projectOptions, "Select a project:", lazy)
 	if err != nil {
-		logger.Fatalf("Failed to list tasks: %v", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("prompt error: %w", err)
 	}
+	return &assignments[idx], nil
+}
 
-	taskOptions := make([]string, len(tasks))
-	for i, t := range tasks {
-		taskOptions[i] = t.Name
+// selectTask returns the project's task with preferredID, or prompts for one
+// when it is zero or not an active task of the project. It reports false
+// when the project has no active tasks.
+func selectTask(a *app, pa *harvest.ProjectAssignment, preferredID int64) (harvest.Task, bool, error) {
+	tasks := pa.Tasks()
+	if len(tasks) == 0 {
+		fmt.Printf("No active tasks are assigned to project %s.\n", pa.Project.Name)
+		return harvest.Task{}, false, nil
 	}
-
-	var selectedTaskID int64
-	if cfg.TaskID != 0 {
-		found := false
+	if preferredID != 0 {
 		for _, t := range tasks {
-			if t.ID == cfg.TaskID {
-				found = true
-				break
+			if t.ID == preferredID {
+				return t, true, nil
 			}
 		}
-		if found {
-			selectedTaskID = cfg.TaskID
-		}
 	}
-	if selectedTaskID == 0 {
-		var err error
-		idx, err := prompt.SelectPrompt(taskOptions, "Select a task:")
-		if err != nil {
-			logger.Fatalf("prompt error: %v", err)
-			os.Exit(1)
-		}
-		selectedTaskID = tasks[idx].ID
+	taskOptions := make([]string, len(tasks))
+	for i, t := range tasks {
+		taskOptions[i] = t.Name
 	}
+	idx, err := prompt.SelectPrompt(taskOptions, "Select a task:")
+	if err != nil {
+		return harvest.Task{}, false, fmt.Errorf("prompt error: %w", err)
+	}
+	return tasks[idx], true, nil
+}
 
-	// Notes input
-	notes := note
+// promptNotes returns the notes from the -n and -t flags, prompting when
+// neither was given.
+func promptNotes(a *app, opts startOptions) (string, error) {
+	if opts.ticket != "" {
+		return withTicket(opts.note, opts.ticket), nil
+	}
+	notes := opts.note
 	if notes == "" {
 		var err error
 		notes, err = prompt.InputPrompt("Enter notes:", "")
 		if err != nil {
-			logger.Fatalf("prompt error: %v", err)
-			os.Exit(1)
+			return "", fmt.Errorf("prompt error: %w", err)
 		}
 	}
+	// A ticket from a branch rule prefixes the notes like -t, but the notes
+	// are still asked for.
+	return withTicket(notes, a.resolved.Ticket), nil
+}
+
+// setEntryTiming fills in the hours or start and end times of a completed
+// entry, following the account's tracking mode. Duration accounts take
+// hours, so ranges are converted. Timestamp accounts take times, so a plain
+// duration on today ends now, and other days need an explicit range.
+func setEntryTiming(req *harvest.TimeEntryRequest, company *harvest.Company, span *timeparse.Span, date, now time.Time) error {
+	if !company.WantsTimestampTimers {
+		var hours float64
+		if span != nil {
+			hours = span.Hours
+		}
+		req.Hours = &hours
+		return nil
+	}
 
-	// Create time entry
-	req := harvest.TimeEntryRequest{ProjectID: selectedProjectID, TaskID: selectedTaskID, SpendDate: time.Now().Format(time.RFC3339), Notes: notes}
-	resp, err := client.CreateTimeEntry(req)
+	if span == nil {
+		return fmt.Errorf("this account tracks start and end times; pass --duration with a range such as 09:00-10:30 to log another day")
+	}
+	start, end := span.Start, span.End
+	if !span.IsRange {
+		if !date.Equal(timeparse.Day(now)) {
+			return fmt.Errorf("this account tracks start and end times; give --duration as a range such as 09:00-10:30 for days other than today")
+		}
+		end = now.Sub(date).Truncate(time.Minute)
+		start = end - time.Duration(span.Hours*float64(time.Hour)).Round(time.Minute)
+		if start < 0 {
+			return fmt.Errorf("that duration would start before midnight; give --duration as a range instead")
+		}
+	}
+	startedTime := timeparse.FormatClock(start, company.ClockFormat)
+	endedTime := timeparse.FormatClock(end, company.ClockFormat)
+	req.StartedTime = &startedTime
+	req.EndedTime = &endedTime
+	return nil
+}
+
+// withTicket prefixes note with the ticket number on its own line.
+func withTicket(note, ticket string) string {
+	if ticket == "" {
+		return note
+	}
+	// Ensure ticket starts with '#'
+	if !strings.HasPrefix(ticket, "#") {
+		ticket = "#" + ticket
+	}
+	prefix := fmt.Sprintf("%s\n", ticket)
+	if note != "" {
+		return prefix + note
+	}
+	return prefix
+}
+
+func main() {
+	// Setup logger
+	logger, err := config.SetupLogger()
 	if err != nil {
-		logger.Fatalf("Failed to create time entry: %v", err)
-		os.Exit(1)
+		log.Fatalf("Failed to setup logger: %v", err)
 	}
 
-	fmt.Printf("Created time entry ID %d for project %s task %s\n", resp.ID, resp.Project.Name, resp.Task.Name)
+	// Cancel in-flight requests on Ctrl+C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Save defaults
-	cfg.ProjectID = selectedProjectID
-	cfg.TaskID = selectedTaskID
-	if !ignoreConfig {
-		if err := cfg.Save(configPath); err != nil {
-			logger.Printf("Failed to save config: %v", err)
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd := findCommand(args[0])
+		if cmd == nil {
+			fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+			printUsage()
+			os.Exit(2)
 		}
+		err = cmd.run(ctx, logger, args[1:])
+	} else {
+		// No command: the original single-letter flags, starting a timer by default
+		err = runLegacy(ctx, logger, args)
+	}
+	if err != nil {
+		exit(logger, err)
 	}
-
 }
@@ -0,0 +1,817 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/harvestcli/internal/config"
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/harvest/harvesttest"
+	"github.com/example/harvestcli/internal/timeparse"
+)
+
+var discard = log.New(io.Discard, "", 0)
+
+func notes(s string) *string { return &s }
+
+func TestSplitHours(t *testing.T) {
+	tests := []struct {
+		in             float64
+		hours, minutes int
+	}{
+		{0, 0, 0},
+		{1.5, 1, 30},
+		{1.1, 1, 6},
+		{0.01, 0, 1},
+		{2.25, 2, 15},
+		{0.999, 1, 0},
+		{7.995, 8, 0},
+	}
+	for _, tt := range tests {
+		h, m := splitHours(tt.in)
+		if h != tt.hours || m != tt.minutes {
+			t.Errorf("splitHours(%v) = %d, %d, want %d, %d", tt.in, h, m, tt.hours, tt.minutes)
+		}
+	}
+}
+
+func TestFormatStatus(t *testing.T) {
+	running := []harvest.TimeEntry{
+		{Hours: 0.5, Billable: true},
+		{Hours: 1.25, IsRunning: true, Notes: notes("#123\nFix login\nmore")},
+	}
+	paused := []harvest.TimeEntry{
+		{Hours: 1.5, Billable: true},
+		{Hours: 2, Billable: false},
+		{Hours: 0.25, Billable: true},
+	}
+	tests := []struct {
+		name            string
+		entries         []harvest.TimeEntry
+		sketchy, waybar bool
+		want            string
+	}{
+		{"running tmux", running, false, false, "#[fg=colour46][01:15]#[default] #123"},
+		{"running sketchybar", running, true, false, "[01:15] #123\n"},
+		{"running waybar", running, false, true, `{"text":"<span color='#00ff00'>[01:15]</span> <span color='#ffffff'>#123</span>","class":"running"}` + "\n"},
+		{"running without notes", []harvest.TimeEntry{{Hours: 0.1, IsRunning: true}}, true, false, "[00:06]\n"},
+		{"paused tmux", paused, false, false, "#[fg=colour46][01:45]#[default] paused"},
+		{"paused sketchybar", paused, true, false, "[01:45] paused\n"},
+		{"paused waybar", paused, false, true, `{"text":"<span color='#ff0000'>[01:45]</span> paused","class":"paused"}` + "\n"},
+		{"nothing today", nil, true, false, "[00:00] paused\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatStatus(tt.entries, tt.sketchy, tt.waybar); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func entriesMock(entries ...harvest.TimeEntry) *harvesttest.Mock {
+	return &harvesttest.Mock{
+		CompanyFunc: func(ctx context.Context) (*harvest.Company, error) {
+			return &harvest.Company{ClockFormat: "24h"}, nil
+		},
+		ListTimeEntriesFunc: func(ctx context.Context, filter harvest.TimeEntryFilter) ([]harvest.TimeEntry, error) {
+			return entries, nil
+		},
+		GetTimeEntryFunc: func(ctx context.Context, id int64) (*harvest.TimeEntry, error) {
+			for _, e := range entries {
+				if e.ID == id {
+					return &e, nil
+				}
+			}
+			return nil, &harvest.APIError{StatusCode: 404}
+		},
+		UpdateTimeEntryFunc: func(ctx context.Context, id int64, update harvest.TimeEntryUpdateRequest) (*harvest.TimeEntry, error) {
+			for _, e := range entries {
+				if e.ID == id {
+					e.Hours = *update.Hours
+					return &e, nil
+				}
+			}
+			return nil, &harvest.APIError{StatusCode: 404}
+		},
+	}
+}
+
+func TestHandleAddTime(t *testing.T) {
+	website := harvest.Project{Name: "Website"}
+	dev := harvest.Task{Name: "Dev"}
+	tests := []struct {
+		name     string
+		entries  []harvest.TimeEntry
+		adj      timeAdjustment
+		wantOut  string
+		wantCode int // 0 for success
+		updated  bool
+	}{
+		{
+			name:    "adds to the running timer",
+			entries: []harvest.TimeEntry{{ID: 1, Hours: 0.5, IsRunning: true, Project: website, Task: dev}},
+			adj:     timeAdjustment{delta: 0.25},
+			wantOut: "Added 15 minutes to running timer. New total: [00:45] for project Website task Dev\n",
+			updated: true,
+		},
+		{
+			name:    "subtracts from an entry",
+			entries: []harvest.TimeEntry{{ID: 2, Hours: 1, Project: website, Task: dev}},
+			adj:     timeAdjustment{delta: -0.5, entryID: 2},
+			wantOut: "Subtracted 30 minutes from time entry. New total: [00:30] for project Website task Dev\n",
+			updated: true,
+		},
+		{
+			name:    "sets an entry",
+			entries: []harvest.TimeEntry{{ID: 2, Hours: 1, Project: website, Task: dev}},
+			adj:     timeAdjustment{set: new(float64), entryID: 2},
+			wantOut: "Set time entry to [00:00] for project Website task Dev\n",
+			updated: true,
+		},
+		{
+			name:    "no running timer",
+			entries: []harvest.TimeEntry{{ID: 1, Hours: 0.5}},
+			adj:     timeAdjustment{delta: 0.25},
+			wantOut: "No running timer found to add time to.\n",
+		},
+		{
+			name:     "locked entry",
+			entries:  []harvest.TimeEntry{{ID: 3, Hours: 1, IsLocked: true, LockedReason: notes("Timesheet approved")}},
+			adj:      timeAdjustment{delta: 0.25, entryID: 3},
+			wantCode: exitLocked,
+		},
+		{
+			name:     "invoiced entry",
+			entries:  []harvest.TimeEntry{{ID: 3, Hours: 1, IsBilled: true}},
+			adj:      timeAdjustment{delta: 0.25, entryID: 3},
+			wantCode: exitLocked,
+		},
+		{
+			name:     "below zero",
+			entries:  []harvest.TimeEntry{{ID: 1, Hours: 0.25, IsRunning: true}},
+			adj:      timeAdjustment{delta: -0.5},
+			wantCode: exitError,
+		},
+		{
+			name:     "missing entry",
+			adj:      timeAdjustment{delta: 0.25, entryID: 9},
+			wantCode: exitNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := entriesMock(tt.entries...)
+			var out bytes.Buffer
+			err := handleAddTime(context.Background(), &out, mock, 1, discard, tt.adj)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else if code, _ := exitStatus(err); err == nil || code != tt.wantCode {
+				t.Fatalf("got error %v (exit %d), want exit %d", err, code, tt.wantCode)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output %q, want %q", out.String(), tt.wantOut)
+			}
+			if got := slices.Contains(mock.Calls(), "UpdateTimeEntry"); got != tt.updated {
+				t.Errorf("UpdateTimeEntry called = %v, want %v (calls %v)", got, tt.updated, mock.Calls())
+			}
+		})
+	}
+}
+
+func TestHandleAddTimeLockedMessage(t *testing.T) {
+	mock := entriesMock(harvest.TimeEntry{ID: 3, Hours: 1, IsLocked: true, LockedReason: notes("Timesheet approved")})
+	err := handleAddTime(context.Background(), io.Discard, mock, 1, discard, timeAdjustment{delta: 0.25, entryID: 3})
+	if want := "Time entry 3 can't be changed: Timesheet approved."; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
+
+func TestHandleAddTimeTimestamps(t *testing.T) {
+	quarter := 0.25
+	tests := []struct {
+		name      string
+		entry     harvest.TimeEntry
+		adj       timeAdjustment
+		wantTimes string // started-ended sent to Harvest
+		wantOut   string
+		wantErr   string
+	}{
+		{
+			name:      "moves the end time",
+			entry:     harvest.TimeEntry{ID: 2, Hours: 1, StartedTime: notes("9:00am"), EndedTime: notes("10:00am")},
+			adj:       timeAdjustment{delta: 0.5, entryID: 2},
+			wantTimes: "9:00am-10:30am",
+			wantOut:   "Added 30 minutes to time entry. New total: [01:30] for project Website task Dev\n",
+		},
+		{
+			name:      "sets the length",
+			entry:     harvest.TimeEntry{ID: 2, Hours: 1, StartedTime: notes("1:15pm"), EndedTime: notes("2:15pm")},
+			adj:       timeAdjustment{set: &quarter, entryID: 2},
+			wantTimes: "1:15pm-1:30pm",
+			wantOut:   "Set time entry to [00:15] for project Website task Dev\n",
+		},
+		{
+			name:    "running timer",
+			entry:   harvest.TimeEntry{ID: 1, Hours: 1, IsRunning: true, StartedTime: notes("9:00am")},
+			adj:     timeAdjustment{delta: 0.25, entryID: 1},
+			wantErr: "This account tracks start and end times, so time can't be added to a running timer. Stop it and correct its times with 'harvest_cli edit'.",
+		},
+		{
+			name:    "past midnight",
+			entry:   harvest.TimeEntry{ID: 2, Hours: 1, StartedTime: notes("11:00pm"), EndedTime: notes("11:30pm")},
+			adj:     timeAdjustment{delta: 1, entryID: 2},
+			wantErr: "Time entry 2 would end after midnight. Set its times with 'harvest_cli edit'.",
+		},
+		{
+			name:    "no start time",
+			entry:   harvest.TimeEntry{ID: 2, Hours: 1},
+			adj:     timeAdjustment{delta: 1, entryID: 2},
+			wantErr: "Time entry 2 has no start time. Set its times with 'harvest_cli edit'.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := entriesMock(tt.entry)
+			mock.CompanyFunc = func(ctx context.Context) (*harvest.Company, error) {
+				return &harvest.Company{WantsTimestampTimers: true, ClockFormat: "12h"}, nil
+			}
+			var gotTimes string
+			mock.UpdateTimeEntryFunc = func(ctx context.Context, id int64, update harvest.TimeEntryUpdateRequest) (*harvest.TimeEntry, error) {
+				if update.Hours != nil || update.StartedTime == nil || update.EndedTime == nil {
+					t.Fatalf("unexpected update %+v", update)
+				}
+				gotTimes = *update.StartedTime + "-" + *update.EndedTime
+				span, err := timeparse.ParseSpan(gotTimes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return &harvest.TimeEntry{ID: id, Hours: span.Hours, Project: harvest.Project{Name: "Website"}, Task: harvest.Task{Name: "Dev"}}, nil
+			}
+			var out bytes.Buffer
+			err := handleAddTime(context.Background(), &out, mock, 1, discard, tt.adj)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				if slices.Contains(mock.Calls(), "UpdateTimeEntry") {
+					t.Errorf("updated the entry after a failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotTimes != tt.wantTimes {
+				t.Errorf("sent times %q, want %q", gotTimes, tt.wantTimes)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestHandleStopTimer(t *testing.T) {
+	t.Run("stops the running entry", func(t *testing.T) {
+		mock := entriesMock(harvest.TimeEntry{ID: 1}, harvest.TimeEntry{ID: 2, IsRunning: true})
+		var stopped int64
+		mock.StopTimeEntryFunc = func(ctx context.Context, id int64) (*harvest.TimeEntry, error) {
+			stopped = id
+			return &harvest.TimeEntry{ID: id, Project: harvest.Project{Name: "Website"}, Task: harvest.Task{Name: "Dev"}}, nil
+		}
+		var out bytes.Buffer
+		if err := handleStopTimer(context.Background(), &out, mock, 1, discard); err != nil {
+			t.Fatal(err)
+		}
+		if stopped != 2 {
+			t.Errorf("stopped entry %d, want 2", stopped)
+		}
+		if want := "Stopped time entry 2 for project Website task Dev\n"; out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+	})
+	t.Run("nothing running", func(t *testing.T) {
+		mock := entriesMock(harvest.TimeEntry{ID: 1})
+		var out bytes.Buffer
+		if err := handleStopTimer(context.Background(), &out, mock, 1, discard); err != nil {
+			t.Fatal(err)
+		}
+		if want := "No running timer found to stop.\n"; out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+	})
+	t.Run("API error", func(t *testing.T) {
+		mock := &harvesttest.Mock{
+			ListTimeEntriesFunc: func(ctx context.Context, filter harvest.TimeEntryFilter) ([]harvest.TimeEntry, error) {
+				return nil, &harvest.APIError{StatusCode: 401}
+			},
+		}
+		err := handleStopTimer(context.Background(), io.Discard, mock, 1, discard)
+		if code, hint := exitStatus(err); code != exitUnauthorized || !strings.Contains(hint, "config setup") {
+			t.Errorf("got exit %d, hint %q for %v", code, hint, err)
+		}
+	})
+}
+
+func TestHandleToggle(t *testing.T) {
+	t.Run("stops the running timer", func(t *testing.T) {
+		mock := entriesMock(harvest.TimeEntry{ID: 1, Hours: 1, Billable: true, IsRunning: true})
+		mock.StopTimeEntryFunc = func(ctx context.Context, id int64) (*harvest.TimeEntry, error) {
+			return &harvest.TimeEntry{ID: id, Hours: 1, Billable: true}, nil
+		}
+		var out bytes.Buffer
+		if err := handleToggle(context.Background(), &out, mock, 1, discard, true, false); err != nil {
+			t.Fatal(err)
+		}
+		if want := "[01:00] paused\n"; out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+	})
+	t.Run("resumes the latest editable entry", func(t *testing.T) {
+		mock := entriesMock(
+			harvest.TimeEntry{ID: 1, Hours: 1, UpdatedAt: "2026-10-17T09:00:00Z"},
+			harvest.TimeEntry{ID: 2, Hours: 2, UpdatedAt: "2026-10-17T11:00:00Z"},
+			harvest.TimeEntry{ID: 3, Hours: 3, UpdatedAt: "2026-10-17T12:00:00Z", IsBilled: true},
+		)
+		var restarted int64
+		mock.RestartTimeEntryFunc = func(ctx context.Context, id int64) (*harvest.TimeEntry, error) {
+			restarted = id
+			return &harvest.TimeEntry{ID: id, Hours: 2, IsRunning: true, Notes: notes("Review")}, nil
+		}
+		var out bytes.Buffer
+		if err := handleToggle(context.Background(), &out, mock, 1, discard, true, false); err != nil {
+			t.Fatal(err)
+		}
+		if restarted != 2 {
+			t.Errorf("restarted entry %d, want 2", restarted)
+		}
+		if want := "[02:00] Review\n"; out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+	})
+	t.Run("restart fails", func(t *testing.T) {
+		mock := entriesMock(harvest.TimeEntry{ID: 1})
+		mock.RestartTimeEntryFunc = func(ctx context.Context, id int64) (*harvest.TimeEntry, error) {
+			return nil, &harvest.APIError{StatusCode: 422}
+		}
+		var out bytes.Buffer
+		err := handleToggle(context.Background(), &out, mock, 1, discard, false, false)
+		if code, _ := exitStatus(err); code != exitLocked {
+			t.Errorf("got exit %d for %v, want %d", code, err, exitLocked)
+		}
+		if out.Len() != 0 {
+			t.Errorf("printed %q after a failure", out.String())
+		}
+	})
+}
+
+func TestHandleExpenseCreate(t *testing.T) {
+	receipt := filepath.Join(t.TempDir(), "lunch.pdf")
+	if err := os.WriteFile(receipt, []byte("%PDF"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	created := func(req harvest.ExpenseCreateRequest) *harvest.ExpenseDetail {
+		return &harvest.ExpenseDetail{
+			ID:              5,
+			SpentDate:       req.SpentDate,
+			TotalCost:       req.TotalCost,
+			Project:         harvest.Project{Name: "Website"},
+			ExpenseCategory: harvest.ExpenseCategory{Name: "Meals"},
+		}
+	}
+	tests := []struct {
+		name      string
+		projectID string
+		amount    string
+		receipt   string
+		createErr error
+		wantOut   string
+		wantCode  int // 0 for success
+		wantCalls []string
+	}{
+		{
+			name:      "creates the expense",
+			projectID: "7",
+			amount:    "12.5",
+			wantOut:   "Created expense #5: $12.50 - Meals (Website) [2026-10-01]\n",
+			wantCalls: []string{"CreateExpense"},
+		},
+		{
+			name:      "uploads the receipt",
+			projectID: "7",
+			amount:    "12.5",
+			receipt:   receipt,
+			wantOut:   "Created expense #5: $12.50 - Meals (Website) [2026-10-01] (receipt: lunch.pdf)\n",
+			wantCalls: []string{"CreateExpenseWithReceipt"},
+		},
+		{
+			name:      "invalid project ID",
+			projectID: "web",
+			amount:    "12.5",
+			wantCode:  exitError,
+		},
+		{
+			name:      "invalid amount",
+			projectID: "7",
+			amount:    "twelve",
+			wantCode:  exitError,
+		},
+		{
+			name:      "missing receipt",
+			projectID: "7",
+			amount:    "12.5",
+			receipt:   filepath.Join(t.TempDir(), "missing.pdf"),
+			wantCode:  exitError,
+		},
+		{
+			name:      "rejected by Harvest",
+			projectID: "7",
+			amount:    "12.5",
+			createErr: &harvest.APIError{StatusCode: 422},
+			wantCode:  exitLocked,
+			wantCalls: []string{"CreateExpense"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &harvesttest.Mock{
+				CreateExpenseFunc: func(ctx context.Context, req harvest.ExpenseCreateRequest) (*harvest.ExpenseDetail, error) {
+					if req.ProjectID != 7 || req.ExpenseCategoryID != 3 || req.Notes == nil || *req.Notes != "Lunch" {
+						t.Errorf("unexpected request %+v", req)
+					}
+					if tt.createErr != nil {
+						return nil, tt.createErr
+					}
+					return created(req), nil
+				},
+				CreateExpenseWithReceiptFunc: func(ctx context.Context, req harvest.ExpenseCreateRequest, path string) (*harvest.ExpenseDetail, error) {
+					if path != tt.receipt {
+						t.Errorf("uploaded %q, want %q", path, tt.receipt)
+					}
+					return created(req), nil
+				},
+			}
+			var out bytes.Buffer
+			err := handleExpenseCreate(context.Background(), &out, mock, tt.projectID, "3", tt.amount, "2026-10-01", "Lunch", tt.receipt)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else if code, _ := exitStatus(err); err == nil || code != tt.wantCode {
+				t.Fatalf("got error %v (exit %d), want exit %d", err, code, tt.wantCode)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output %q, want %q", out.String(), tt.wantOut)
+			}
+			if !slices.Equal(mock.Calls(), tt.wantCalls) {
+				t.Errorf("calls %v, want %v", mock.Calls(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHandleExpenseList(t *testing.T) {
+	t.Run("lists expenses", func(t *testing.T) {
+		mock := &harvesttest.Mock{
+			ListExpensesFunc: func(ctx context.Context, from, to *string) ([]harvest.ExpenseDetail, error) {
+				return []harvest.ExpenseDetail{{
+					SpentDate:       "2026-10-01",
+					Project:         harvest.Project{Name: "Website"},
+					ExpenseCategory: harvest.ExpenseCategory{Name: "Meals"},
+					TotalCost:       12.5,
+					Notes:           notes("Lunch"),
+				}}, nil
+			},
+		}
+		var out bytes.Buffer
+		if err := handleExpenseList(context.Background(), &out, mock, nil, nil, false); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(out.String(), "\n")
+		if len(lines) < 3 || !strings.HasPrefix(lines[0], "DATE") {
+			t.Fatalf("output %q has no header", out.String())
+		}
+		for _, want := range []string{"2026-10-01", "Website", "Meals", "$12.50", "Lunch"} {
+			if !strings.Contains(lines[2], want) {
+				t.Errorf("row %q is missing %q", lines[2], want)
+			}
+		}
+	})
+	t.Run("no expenses", func(t *testing.T) {
+		mock := &harvesttest.Mock{
+			ListExpensesFunc: func(ctx context.Context, from, to *string) ([]harvest.ExpenseDetail, error) {
+				return nil, nil
+			},
+		}
+		var out bytes.Buffer
+		if err := handleExpenseList(context.Background(), &out, mock, nil, nil, false); err != nil {
+			t.Fatal(err)
+		}
+		if want := "No expenses found.\n"; out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+	})
+	t.Run("API error", func(t *testing.T) {
+		mock := &harvesttest.Mock{
+			ListExpensesFunc: func(ctx context.Context, from, to *string) ([]harvest.ExpenseDetail, error) {
+				return nil, &harvest.APIError{StatusCode: 401}
+			},
+		}
+		err := handleExpenseList(context.Background(), io.Discard, mock, nil, nil, false)
+		if code, _ := exitStatus(err); code != exitUnauthorized {
+			t.Errorf("got exit %d for %v, want %d", code, err, exitUnauthorized)
+		}
+	})
+}
+
+func TestHandleInvoiceList(t *testing.T) {
+	invoices := []harvest.InvoiceDetail{{
+		ID:        9,
+		Number:    "2026-014",
+		Amount:    1200,
+		Status:    "open",
+		IssuedAt:  "2026-10-01",
+		Client:    harvest.HarvestClient{Name: "Acme"},
+		CreatedAt: "2026-09-30T10:00:00Z",
+	}}
+	mock := &harvesttest.Mock{
+		ListInvoicesFunc: func(ctx context.Context, from, to *string) ([]harvest.InvoiceDetail, error) {
+			if from == nil || *from != "2026-10-01" || to != nil {
+				t.Errorf("got range %v - %v", from, to)
+			}
+			return invoices, nil
+		},
+	}
+	from := "2026-10-01"
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := handleInvoiceList(context.Background(), &out, mock, &from, nil, false); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"2026-014", "$1200.00", "open", "Acme"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output %q is missing %q", out.String(), want)
+			}
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := handleInvoiceList(context.Background(), &out, mock, &from, nil, true); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(out.String(), "[") || !strings.Contains(out.String(), `"number": "2026-014"`) {
+			t.Errorf("output %q is not the invoices as JSON", out.String())
+		}
+	})
+}
+
+func TestHandleEntryList(t *testing.T) {
+	mock := entriesMock(
+		harvest.TimeEntry{ID: 1, SpentDate: "2026-10-12", Hours: 1.5, Billable: true, Project: harvest.Project{Name: "Website"}, Task: harvest.Task{Name: "Dev"}, Notes: notes("Header")},
+		harvest.TimeEntry{ID: 2, SpentDate: "2026-10-12", Hours: 0.5, Project: harvest.Project{Name: "Website"}, Task: harvest.Task{Name: "Meetings"}},
+		harvest.TimeEntry{ID: 3, SpentDate: "2026-10-13", Hours: 2, Billable: true, Project: harvest.Project{Name: "Internal"}, Task: harvest.Task{Name: "Dev"}},
+	)
+	billable := true
+	query := entryQuery{Project: "web", Billable: &billable}
+
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := handleEntryList(context.Background(), &out, mock, query, false, false); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(out.String(), "Meetings") || strings.Contains(out.String(), "Internal") {
+			t.Errorf("output %q includes filtered entries", out.String())
+		}
+		if !strings.Contains(out.String(), "Header") || !strings.Contains(out.String(), "TOTAL (1 entry)") {
+			t.Errorf("output %q is missing the matching entry", out.String())
+		}
+	})
+	t.Run("CSV", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := handleEntryList(context.Background(), &out, mock, query, false, true); err != nil {
+			t.Fatal(err)
+		}
+		want := "ID,Date,Client,Project,Task,Hours,Billable,Running,Billed,Notes\n" +
+			"1,2026-10-12,,Website,Dev,1.50,true,false,false,Header\n"
+		if out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+	})
+	t.Run("API error", func(t *testing.T) {
+		mock := &harvesttest.Mock{
+			ListTimeEntriesFunc: func(ctx context.Context, filter harvest.TimeEntryFilter) ([]harvest.TimeEntry, error) {
+				return nil, &harvest.APIError{StatusCode: 404}
+			},
+		}
+		err := handleEntryList(context.Background(), io.Discard, mock, query, false, false)
+		if code, _ := exitStatus(err); code != exitNotFound {
+			t.Errorf("got exit %d for %v, want %d", code, err, exitNotFound)
+		}
+	})
+}
+
+func TestHandleStart(t *testing.T) {
+	assignments := []harvest.ProjectAssignment{{
+		Project: harvest.Project{ID: 7, Name: "Website"},
+		TaskAssignments: []harvest.TaskAssignment{
+			{IsActive: true, Task: harvest.Task{ID: 3, Name: "Dev"}},
+		},
+	}}
+	newStartApp := func(mock *harvesttest.Mock) *app {
+		return &app{
+			ctx:      context.Background(),
+			logger:   discard,
+			opts:     commonOptions{ignoreConfig: true},
+			resolved: &config.Resolved{ProjectID: 7, TaskID: 3},
+			cfg:      &config.Config{},
+			client:   mock,
+		}
+	}
+	startMock := func(company *harvest.Company) *harvesttest.Mock {
+		return &harvesttest.Mock{
+			ListMyProjectAssignmentsFunc: func(ctx context.Context) ([]harvest.ProjectAssignment, error) {
+				return assignments, nil
+			},
+			CompanyFunc: func(ctx context.Context) (*harvest.Company, error) {
+				return company, nil
+			},
+			CreateTimeEntryFunc: func(ctx context.Context, req harvest.TimeEntryRequest) (*harvest.TimeEntryResponse, error) {
+				if req.ProjectID != 7 || req.TaskID != 3 || req.Notes != "Header" {
+					t.Errorf("unexpected request %+v", req)
+				}
+				return &harvest.TimeEntryResponse{ID: 11, SpentDate: req.SpendDate, Project: assignments[0].Project, Task: assignments[0].Tasks()[0]}, nil
+			},
+		}
+	}
+
+	t.Run("starts a timer", func(t *testing.T) {
+		mock := startMock(&harvest.Company{})
+		a := newStartApp(mock)
+		var out bytes.Buffer
+		if err := handleStart(a, &out, startOptions{note: "Header"}); err != nil {
+			t.Fatal(err)
+		}
+		if want := "Created time entry ID 11 for project Website task Dev\n"; out.String() != want {
+			t.Errorf("output %q, want %q", out.String(), want)
+		}
+		if want := []string{"ListMyProjectAssignments", "CreateTimeEntry"}; !slices.Equal(mock.Calls(), want) {
+			t.Errorf("calls %v, want %v", mock.Calls(), want)
+		}
+		if a.cfg.ProjectID != 7 || a.cfg.TaskID != 3 {
+			t.Errorf("saved defaults %d/%d, want 7/3", a.cfg.ProjectID, a.cfg.TaskID)
+		}
+	})
+	t.Run("another day on a timestamp account", func(t *testing.T) {
+		mock := startMock(&harvest.Company{WantsTimestampTimers: true})
+		yesterday := timeparse.Day(time.Now()).AddDate(0, 0, -1)
+		err := handleStart(newStartApp(mock), io.Discard, startOptions{note: "Header", date: yesterday})
+		if code, _ := exitStatus(err); err == nil || code != exitError {
+			t.Fatalf("got error %v (exit %d), want exit %d", err, code, exitError)
+		}
+		if slices.Contains(mock.Calls(), "CreateTimeEntry") {
+			t.Errorf("created an entry after a failure: %v", mock.Calls())
+		}
+	})
+	t.Run("API error", func(t *testing.T) {
+		mock := startMock(&harvest.Company{})
+		mock.CreateTimeEntryFunc = func(ctx context.Context, req harvest.TimeEntryRequest) (*harvest.TimeEntryResponse, error) {
+			return nil, &harvest.APIError{StatusCode: 422}
+		}
+		a := newStartApp(mock)
+		err := handleStart(a, io.Discard, startOptions{note: "Header"})
+		if code, _ := exitStatus(err); code != exitLocked {
+			t.Errorf("got exit %d for %v, want %d", code, err, exitLocked)
+		}
+		if a.cfg.ProjectID != 0 {
+			t.Errorf("saved defaults after a failure")
+		}
+	})
+}
+
+func TestSetEntryTiming(t *testing.T) {
+	now := time.Date(2026, 10, 14, 14, 20, 45, 0, time.Local)
+	today := timeparse.Day(now)
+	yesterday := today.AddDate(0, 0, -1)
+	duration := &timeparse.Span{Hours: 1.5}
+	morning := &timeparse.Span{Hours: 1.5, Start: 9 * time.Hour, End: 10*time.Hour + 30*time.Minute, IsRange: true}
+	durations := &harvest.Company{}
+	timestamps := &harvest.Company{WantsTimestampTimers: true, ClockFormat: "24h"}
+
+	tests := []struct {
+		name       string
+		company    *harvest.Company
+		span       *timeparse.Span
+		date, now  time.Time
+		hours      *float64
+		start, end string
+		wantErr    bool
+	}{
+		{name: "duration account", company: durations, span: duration, date: yesterday, now: now, hours: &duration.Hours},
+		{name: "duration account with a range", company: durations, span: morning, date: today, now: now, hours: &morning.Hours},
+		{name: "duration account without a span", company: durations, date: today, now: now, hours: new(float64)},
+		{name: "duration today ends now", company: timestamps, span: duration, date: today, now: now, start: "12:50", end: "14:20"},
+		{name: "12h clock", company: &harvest.Company{WantsTimestampTimers: true, ClockFormat: "12h"}, span: duration, date: today, now: now, start: "12:50pm", end: "2:20pm"},
+		{name: "range on another day", company: timestamps, span: morning, date: yesterday, now: now, start: "09:00", end: "10:30"},
+		{name: "duration on another day", company: timestamps, span: duration, date: yesterday, now: now, wantErr: true},
+		{name: "duration before midnight", company: timestamps, span: &timeparse.Span{Hours: 2}, date: today, now: today.Add(time.Hour), wantErr: true},
+		{name: "timestamp account without a span", company: timestamps, date: today, now: now, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req harvest.TimeEntryRequest
+			err := setEntryTiming(&req, tt.company, tt.span, tt.date, tt.now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", req)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (req.Hours == nil) != (tt.hours == nil) || req.Hours != nil && *req.Hours != *tt.hours {
+				t.Errorf("Hours = %v, want %v", req.Hours, tt.hours)
+			}
+			var start, end string
+			if req.StartedTime != nil {
+				start = *req.StartedTime
+			}
+			if req.EndedTime != nil {
+				end = *req.EndedTime
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("times = %q-%q, want %q-%q", start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestStoreAccessToken(t *testing.T) {
+	// bin holds a secret-tool that always fails, standing in for a keyring
+	// that is detected but locked.
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "secret-tool"), []byte("#!/bin/sh\necho locked >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		store     string
+		keyring   bool
+		wantToken string
+		wantRef   string
+		wantErr   bool
+	}{
+		{name: "plain", store: config.PlainStore, wantToken: "tok"},
+		{name: "command", store: "command:op read op://x", wantRef: "command:op read op://x"},
+		{name: "file", store: "file", wantRef: "file:"},
+		{name: "auto without a store", store: "auto", wantToken: "tok"},
+		{name: "auto falls back when the keyring fails", store: "auto", keyring: true, wantToken: "tok"},
+		{name: "explicit store failing", store: "secret-tool", keyring: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			if err := os.MkdirAll(filepath.Dir(config.GlobalConfigPath()), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", t.TempDir())
+			t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+			if tt.keyring {
+				t.Setenv("PATH", bin)
+				t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/nonexistent")
+			}
+
+			profile := &config.Profile{HarvestAccessToken: "old", HarvestAccessTokenRef: "pass:old"}
+			err := storeAccessToken(context.Background(), profile, "work", tt.store, "tok")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got profile %+v, want an error", profile)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if profile.HarvestAccessToken != tt.wantToken || !strings.HasPrefix(profile.HarvestAccessTokenRef, tt.wantRef) || (tt.wantRef == "") != (profile.HarvestAccessTokenRef == "") {
+				t.Errorf("profile = %+v, want token %q and ref %q", profile, tt.wantToken, tt.wantRef)
+			}
+			if tt.store == "file" {
+				if got, err := config.ReadSecret(context.Background(), profile.HarvestAccessTokenRef); err != nil || got != "tok" {
+					t.Errorf("reading back %q = %q, %v", profile.HarvestAccessTokenRef, got, err)
+				}
+			}
+		})
+	}
+}
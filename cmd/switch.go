@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/timeparse"
+)
+
+// switchOptions are the flags of the switch command. project and task take
+// an ID or a name and are prompted for when empty.
+type switchOptions struct {
+	startOptions
+	project string
+	task    string
+}
+
+// handleSwitch stops the running timer and starts the chosen project, task
+// and notes, resuming a matching entry from today instead of creating a new
+// one. Both halves are printed so there is a record of what changed.
+func handleSwitch(a *app, opts switchOptions) {
+	today := time.Now().Format(timeparse.DateLayout)
+	userID := a.currentUserID()
+	entries, err := a.client.ListTimeEntries(a.ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
+	if err != nil {
+		fatalAPI(a.logger, "Failed to list time entries", err)
+	}
+	var running *harvest.TimeEntry
+	for i := range entries {
+		if entries[i].IsRunning {
+			running = &entries[i]
+			break
+		}
+	}
+
+	assignments, err := a.client.ListMyProjectAssignments(a.ctx)
+	if err != nil {
+		fatalAPI(a.logger, "Failed to list project assignments", err)
+	}
+	if len(assignments) == 0 {
+		fmt.Println("You are not assigned to any active projects.")
+		return
+	}
+	projectID, err := matchProject(assignments, opts.project)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	pa, err := selectAssignment(a, assignments, projectID, opts.lazy)
+	if err != nil {
+		exit(a.logger, err)
+	}
+	taskID, err := matchTask(pa.Tasks(), opts.task)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	task, ok, err := selectTask(a, pa, taskID)
+	if err != nil {
+		exit(a.logger, err)
+	}
+	if !ok {
+		return
+	}
+	notes, err := promptNotes(a, opts.startOptions)
+	if err != nil {
+		exit(a.logger, err)
+	}
+
+	if running != nil && running.Project.ID == pa.Project.ID && running.Task.ID == task.ID && entryNotes(*running) == notes {
+		fmt.Printf("Already tracking %s / %s.\n", pa.Project.Name, task.Name)
+		return
+	}
+
+	// Stop explicitly rather than relying on Harvest stopping it when the
+	// next timer starts, so the final duration can be reported.
+	if running != nil {
+		stopped, err := a.client.StopTimeEntry(a.ctx, running.ID)
+		if err != nil {
+			fatalAPI(a.logger, "Failed to stop time entry", err)
+		}
+		a.logger.Printf("switch: stopped time entry %d at %.2fh", stopped.ID, stopped.Hours)
+		fmt.Println(switchLine("Stopped", *stopped))
+	}
+
+	var resume *harvest.TimeEntry
+	for i, entry := range entries {
+		if running != nil && entry.ID == running.ID {
+			continue
+		}
+		if entry.Project.ID != pa.Project.ID || entry.Task.ID != task.ID || entryNotes(entry) != notes || entryLockReason(entry) != "" {
+			continue
+		}
+		if resume == nil || entry.UpdatedAt > resume.UpdatedAt {
+			resume = &entries[i]
+		}
+	}
+
+	if resume != nil {
+		restarted, err := a.client.RestartTimeEntry(a.ctx, resume.ID)
+		if err != nil {
+			fatalAPI(a.logger, "Failed to restart time entry", err)
+		}
+		a.logger.Printf("switch: restarted time entry %d", restarted.ID)
+		fmt.Println(switchLine("Resumed", *restarted))
+		return
+	}
+
+	req := harvest.TimeEntryRequest{ProjectID: pa.Project.ID, TaskID: task.ID, SpendDate: today, Notes: notes}
+	resp, err := a.client.CreateTimeEntry(a.ctx, req)
+	if err != nil {
+		fatalAPI(a.logger, "Failed to create time entry", err)
+	}
+	a.logger.Printf("switch: started time entry %d", resp.ID)
+	fmt.Println(switchLine("Started", harvest.TimeEntry{ID: resp.ID, Project: resp.Project, Task: resp.Task, Notes: &notes}))
+}
+
+// switchLine summarises one side of a switch.
+func switchLine(action string, entry harvest.TimeEntry) string {
+	hours, minutes := splitHours(entry.Hours)
+	line := fmt.Sprintf("%-8s %s / %s [%02d:%02d] (entry %d)", action, entry.Project.Name, entry.Task.Name, hours, minutes, entry.ID)
+	if notes := strings.TrimSpace(entryNotes(entry)); notes != "" {
+		first, _, _ := strings.Cut(notes, "\n")
+		line += "  " + first
+	}
+	return line
+}
+
+func entryNotes(entry harvest.TimeEntry) string {
+	if entry.Notes == nil {
+		return ""
+	}
+	return *entry.Notes
+}
+
+// matchProject resolves an ID or a case-insensitive name, or part of one, to
+// a project ID. An empty value returns zero so the user is prompted.
+func matchProject(assignments []harvest.ProjectAssignment, v string) (int64, error) {
+	names := make([]string, len(assignments))
+	ids := make([]int64, len(assignments))
+	for i, pa := range assignments {
+		names[i], ids[i] = pa.Project.Name, pa.Project.ID
+	}
+	return matchNamed("project", names, ids, v)
+}
+
+// matchTask is matchProject for a project's tasks.
+func matchTask(tasks []harvest.Task, v string) (int64, error) {
+	names := make([]string, len(tasks))
+	ids := make([]int64, len(tasks))
+	for i, t := range tasks {
+		names[i], ids[i] = t.Name, t.ID
+	}
+	return matchNamed("task", names, ids, v)
+}
+
+func matchNamed(kind string, names []string, ids []int64, v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		for _, known := range ids {
+			if known == id {
+				return id, nil
+			}
+		}
+		return 0, fmt.Errorf("no %s with ID %d is assigned to you", kind, id)
+	}
+
+	var found []int
+	for i, name := range names {
+		if strings.EqualFold(name, v) {
+			return ids[i], nil
+		}
+		if strings.Contains(strings.ToLower(name), strings.ToLower(v)) {
+			found = append(found, i)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("no %s matches %q", kind, v)
+	case 1:
+		return ids[found[0]], nil
+	}
+	matches := make([]string, len(found))
+	for i, idx := range found {
+		matches[i] = names[idx]
+	}
+	return 0, fmt.Errorf("%q matches several of your %ss: %s", v, kind, strings.Join(matches, ", "))
+}
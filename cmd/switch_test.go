@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestMatchNamed(t *testing.T) {
+	names := []string{"Website", "Website Redesign", "Mobile App", "Internal"}
+	ids := []int64{1, 2, 3, 4}
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr string
+	}{
+		{in: "", want: 0},
+		{in: "3", want: 3},
+		{in: "9", wantErr: "no project with ID 9 is assigned to you"},
+		{in: "website", want: 1},
+		{in: "WEBSITE REDESIGN", want: 2},
+		{in: "redesign", want: 2},
+		{in: "app", want: 3},
+		{in: "web", wantErr: `"web" matches several of your projects: Website, Website Redesign`},
+		{in: "T", wantErr: `"T" matches several of your projects: Website, Website Redesign, Internal`},
+		{in: "billing", wantErr: `no project matches "billing"`},
+	}
+	for _, tt := range tests {
+		got, err := matchNamed("project", names, ids, tt.in)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("matchNamed(%q) = %d, %v, want error %q", tt.in, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("matchNamed(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/idle"
+	"github.com/example/harvestcli/internal/prompt"
+	"github.com/example/harvestcli/internal/timeparse"
+)
+
+// handleWatch watches src until interrupted. Each time the user comes back
+// from at least threshold of idle time with a timer running, it asks whether
+// to keep, discard or split off the time they were away. Failed Harvest
+// calls are reported and watching carries on.
+func handleWatch(a *app, src idle.Source, threshold, interval time.Duration) {
+	userID := a.currentUserID()
+	errs := make(chan error, 1)
+	events := idle.Watch(a.ctx, src, threshold, interval, time.Now, errs)
+	fmt.Printf("Watching for more than %s of idle time. Press ctrl+c to stop.\n", threshold)
+
+	for {
+		select {
+		case err := <-errs:
+			a.logger.Print(err)
+			fmt.Fprintln(os.Stderr, err)
+		case away, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := handleAway(a, userID, away, threshold); err != nil && a.ctx.Err() == nil {
+				report(a.logger, err)
+			}
+		}
+	}
+}
+
+// handleAway offers to keep, discard or split off an away period counted
+// against the running timer.
+func handleAway(a *app, userID int64, away idle.Away, threshold time.Duration) error {
+	today := time.Now().Format(timeparse.DateLayout)
+	entries, err := a.client.ListTimeEntries(a.ctx, harvest.TimeEntryFilter{From: &today, To: &today, UserID: &userID})
+	if err != nil {
+		return &apiError{action: "Failed to list time entries", err: err}
+	}
+	var running *harvest.TimeEntry
+	for i := range entries {
+		if entries[i].IsRunning {
+			running = &entries[i]
+			break
+		}
+	}
+	if running == nil {
+		a.logger.Printf("idle: away %s with no running timer", away.Duration())
+		return nil
+	}
+
+	// Only the part of the absence after the timer (re)started and since
+	// midnight was counted against it.
+	if running.TimerStartedAt != nil {
+		if started, err := time.Parse(time.RFC3339, *running.TimerStartedAt); err == nil && started.After(away.Start) {
+			away.Start = started
+		}
+	}
+	if midnight := timeparse.Day(away.End); away.Start.Before(midnight) {
+		away.Start = midnight
+	}
+	if tracked := time.Duration(running.Hours * float64(time.Hour)); away.Duration() > tracked {
+		away.Start = away.End.Add(-tracked)
+	}
+	if away.Duration() < threshold {
+		a.logger.Printf("idle: ignoring %s away for time entry %d", away.Duration(), running.ID)
+		return nil
+	}
+	awayHours := away.Duration().Hours()
+
+	company, err := a.client.Company(a.ctx)
+	if err != nil {
+		return &apiError{action: "Failed to fetch account settings", err: err}
+	}
+	hours, minutes := splitHours(awayHours)
+	summary := fmt.Sprintf("You were away for [%02d:%02d] (%s-%s) while %s / %s was running.",
+		hours, minutes, away.Start.Format("15:04"), away.End.Format("15:04"), running.Project.Name, running.Task.Name)
+	fmt.Print("\a")
+	if company.WantsTimestampTimers {
+		// Hours can't be set on these accounts, and moving the start time
+		// would take the time off the wrong end of the entry.
+		a.logger.Printf("idle: away %s on timestamp account, time entry %d left as is", away.Duration(), running.ID)
+		fmt.Println(summary)
+		fmt.Println("This account tracks start and end times, so that time can't be taken off a running timer.")
+		fmt.Println("Stop it and correct its times with 'harvest_cli edit'.")
+		return nil
+	}
+	choice, err := prompt.SelectPrompt([]string{
+		"Keep it",
+		"Discard it",
+		"Split it into a separate entry",
+	}, summary+" What should happen to that time?")
+	if err != nil {
+		a.logger.Fatalf("prompt error: %v", err)
+		os.Exit(1)
+	}
+
+	switch choice {
+	case 0:
+		a.logger.Printf("idle: kept %s on time entry %d", away.Duration(), running.ID)
+		return nil
+	case 2:
+		if split, err := splitAway(a, company, away); !split {
+			return err
+		}
+	}
+
+	remaining := running.Hours - awayHours
+	updated, err := a.client.UpdateTimeEntry(a.ctx, running.ID, harvest.TimeEntryUpdateRequest{Hours: &remaining})
+	if err != nil {
+		return &apiError{action: "Failed to update time entry", err: err}
+	}
+	a.logger.Printf("idle: removed %s from time entry %d", away.Duration(), running.ID)
+	h, m := splitHours(updated.Hours)
+	fmt.Printf("Removed [%02d:%02d] from %s / %s. New total: [%02d:%02d]\n",
+		hours, minutes, updated.Project.Name, updated.Task.Name, h, m)
+	return nil
+}
+
+// splitAway logs the away period as its own entry on a project and task the
+// user picks. It reports false if nothing was created.
+func splitAway(a *app, company *harvest.Company, away idle.Away) (bool, error) {
+	assignments, err := a.client.ListMyProjectAssignments(a.ctx)
+	if err != nil {
+		return false, &apiError{action: "Failed to list project assignments", err: err}
+	}
+	if len(assignments) == 0 {
+		fmt.Println("You are not assigned to any active projects.")
+		return false, nil
+	}
+	pa, err := selectAssignment(a, assignments, 0, false)
+	if err != nil {
+		return false, err
+	}
+	task, ok, err := selectTask(a, pa, 0)
+	if err != nil || !ok {
+		return false, err
+	}
+	notes, err := prompt.InputPrompt("Enter notes:", "")
+	if err != nil {
+		return false, fmt.Errorf("prompt error: %w", err)
+	}
+
+	day := timeparse.Day(away.End)
+	span := timeparse.Span{
+		Hours:   away.Duration().Hours(),
+		Start:   away.Start.Sub(day).Truncate(time.Minute),
+		End:     away.End.Sub(day).Truncate(time.Minute),
+		IsRange: true,
+	}
+	req := harvest.TimeEntryRequest{ProjectID: pa.Project.ID, TaskID: task.ID, SpendDate: day.Format(timeparse.DateLayout), Notes: notes}
+	if err := setEntryTiming(&req, company, &span, day, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false, nil
+	}
+	resp, err := a.client.CreateTimeEntry(a.ctx, req)
+	if err != nil {
+		return false, &apiError{action: "Failed to create time entry", err: err}
+	}
+	a.logger.Printf("idle: split %s into time entry %d", away.Duration(), resp.ID)
+	fmt.Printf("Created time entry ID %d for project %s task %s\n", resp.ID, resp.Project.Name, resp.Task.Name)
+	return true, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/timeparse"
+)
+
+// weekRow is one project and task line of the week view.
+type weekRow struct {
+	ProjectID int64      `json:"project_id"`
+	Project   string     `json:"project"`
+	Client    string     `json:"client"`
+	TaskID    int64      `json:"task_id"`
+	Task      string     `json:"task"`
+	Hours     [7]float64 `json:"hours"`
+	Total     float64    `json:"total"`
+}
+
+// weekSheet is a project×task by day grid of one week's time entries.
+type weekSheet struct {
+	Start       string     `json:"start"`
+	End         string     `json:"end"`
+	Days        [7]string  `json:"days"`
+	Rows        []weekRow  `json:"rows"`
+	DailyTotals [7]float64 `json:"daily_totals"`
+	Total       float64    `json:"total"`
+	DailyTarget float64    `json:"daily_target"`
+}
+
+// buildWeek sums entries into a grid for the seven days from start.
+func buildWeek(entries []harvest.TimeEntry, start time.Time, target float64) weekSheet {
+	sheet := weekSheet{
+		Start:       start.Format(timeparse.DateLayout),
+		End:         start.AddDate(0, 0, 6).Format(timeparse.DateLayout),
+		Rows:        []weekRow{},
+		DailyTarget: target,
+	}
+	index := make(map[string]int, 7)
+	for i := range sheet.Days {
+		sheet.Days[i] = start.AddDate(0, 0, i).Format(timeparse.DateLayout)
+		index[sheet.Days[i]] = i
+	}
+
+	type key struct{ project, task int64 }
+	rows := make(map[key]*weekRow)
+	for _, entry := range entries {
+		day, ok := index[entry.SpentDate]
+		if !ok {
+			continue
+		}
+		k := key{entry.Project.ID, entry.Task.ID}
+		row := rows[k]
+		if row == nil {
+			row = &weekRow{
+				ProjectID: entry.Project.ID,
+				Project:   entry.Project.Name,
+				Client:    entry.Client.Name,
+				TaskID:    entry.Task.ID,
+				Task:      entry.Task.Name,
+			}
+			rows[k] = row
+		}
+		row.Hours[day] += entry.Hours
+		row.Total += entry.Hours
+		sheet.DailyTotals[day] += entry.Hours
+		sheet.Total += entry.Hours
+	}
+
+	for _, row := range rows {
+		sheet.Rows = append(sheet.Rows, *row)
+	}
+	sort.Slice(sheet.Rows, func(i, j int) bool {
+		a, b := sheet.Rows[i], sheet.Rows[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Task < b.Task
+	})
+	return sheet
+}
+
+// handleWeek shows the week containing day as a timesheet grid.
+func handleWeek(a *app, day time.Time, target float64, jsonOutput, csvOutput bool) {
+	company, err := a.client.Company(a.ctx)
+	if err != nil {
+		fatalAPI(a.logger, "Failed to fetch account settings", err)
+	}
+	first, ok := timeparse.Weekday(company.WeekStartDay)
+	if !ok {
+		first = time.Monday
+	}
+	start := timeparse.WeekStart(day, first)
+	from := start.Format(timeparse.DateLayout)
+	to := start.AddDate(0, 0, 6).Format(timeparse.DateLayout)
+
+	userID := a.currentUserID()
+	entries, err := a.client.ListTimeEntries(a.ctx, harvest.TimeEntryFilter{From: &from, To: &to, UserID: &userID})
+	if err != nil {
+		fatalAPI(a.logger, "Failed to list time entries", err)
+	}
+
+	if target == 0 {
+		target = a.profile.DailyTargetHours
+	}
+	if target == 0 {
+		me, err := a.client.Me(a.ctx)
+		if err != nil {
+			fatalAPI(a.logger, "Failed to look up current user", err)
+		}
+		target = me.WeeklyCapacityHours() / 5
+	}
+
+	sheet := buildWeek(entries, start, target)
+
+	switch {
+	case jsonOutput:
+		out, err := jsonMarshal(sheet)
+		if err != nil {
+			a.logger.Fatalf("Failed to marshal week: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	case csvOutput:
+		if err := writeWeekCSV(sheet); err != nil {
+			a.logger.Fatalf("Failed to write CSV: %v", err)
+			os.Exit(1)
+		}
+	default:
+		printWeek(sheet, start, time.Now())
+	}
+}
+
+// printWeek renders the grid for a terminal. Past and current working days
+// whose total is under the daily target are shown in red.
+func printWeek(sheet weekSheet, start, now time.Time) {
+	const labelWidth = 32
+	hm := func(h float64) string {
+		if h == 0 {
+			return "-"
+		}
+		hours, minutes := splitHours(h)
+		return fmt.Sprintf("%d:%02d", hours, minutes)
+	}
+
+	fmt.Printf("Week of %s to %s\n\n", sheet.Start, sheet.End)
+	fmt.Printf("%-*s", labelWidth, "PROJECT / TASK")
+	for i := range sheet.Days {
+		d := start.AddDate(0, 0, i)
+		fmt.Printf(" %7s", d.Format("Mon 02"))
+	}
+	fmt.Printf(" %8s\n", "TOTAL")
+	width := labelWidth + 8*7 + 9
+	fmt.Println(strings.Repeat("-", width))
+
+	if len(sheet.Rows) == 0 {
+		fmt.Println("No time entries found.")
+	}
+	for _, row := range sheet.Rows {
+		label := row.Project + " / " + row.Task
+		if len(label) > labelWidth-1 {
+			label = label[:labelWidth-4] + "..."
+		}
+		fmt.Printf("%-*s", labelWidth, label)
+		for _, h := range row.Hours {
+			fmt.Printf(" %7s", hm(h))
+		}
+		fmt.Printf(" %8s\n", hm(row.Total))
+	}
+
+	fmt.Println(strings.Repeat("-", width))
+	fmt.Printf("%-*s", labelWidth, "TOTAL")
+	today := timeparse.Day(now)
+	for i, h := range sheet.DailyTotals {
+		d := start.AddDate(0, 0, i)
+		cell := fmt.Sprintf(" %7s", hm(h))
+		workday := d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+		if workday && !d.After(today) && h < sheet.DailyTarget {
+			cell = "\033[31m" + cell + "\033[0m"
+		}
+		fmt.Print(cell)
+	}
+	fmt.Printf(" %8s\n", hm(sheet.Total))
+
+	fmt.Printf("\nDaily target %s, weekly %s\n", hm(sheet.DailyTarget), hm(sheet.DailyTarget*5))
+}
+
+// writeWeekCSV writes the grid as CSV with decimal hours.
+func writeWeekCSV(sheet weekSheet) error {
+	w := csv.NewWriter(os.Stdout)
+	hours := func(h float64) string { return strconv.FormatFloat(h, 'f', 2, 64) }
+
+	header := []string{"Project", "Client", "Task"}
+	header = append(header, sheet.Days[:]...)
+	header = append(header, "Total")
+	w.Write(header)
+
+	for _, row := range sheet.Rows {
+		record := []string{row.Project, row.Client, row.Task}
+		for _, h := range row.Hours {
+			record = append(record, hours(h))
+		}
+		w.Write(append(record, hours(row.Total)))
+	}
+
+	record := []string{"Total", "", ""}
+	for _, h := range sheet.DailyTotals {
+		record = append(record, hours(h))
+	}
+	w.Write(append(record, hours(sheet.Total)))
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+func TestBuildWeek(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	website := harvest.Project{ID: 1, Name: "Website"}
+	internal := harvest.Project{ID: 2, Name: "Internal"}
+	dev := harvest.Task{ID: 10, Name: "Dev"}
+	design := harvest.Task{ID: 11, Name: "Design"}
+	entry := func(date string, hours float64, project harvest.Project, task harvest.Task) harvest.TimeEntry {
+		return harvest.TimeEntry{SpentDate: date, Hours: hours, Project: project, Task: task}
+	}
+
+	tests := []struct {
+		name      string
+		entries   []harvest.TimeEntry
+		wantRows  []weekRow
+		wantDaily [7]float64
+		wantTotal float64
+	}{
+		{
+			name:     "no entries",
+			wantRows: []weekRow{},
+		},
+		{
+			name: "skips entries outside the week",
+			entries: []harvest.TimeEntry{
+				entry("2026-10-11", 2, website, dev),
+				entry("2026-10-12", 1, website, dev),
+				entry("2026-10-18", 0.5, website, dev),
+				entry("2026-10-19", 3, website, dev),
+			},
+			wantRows: []weekRow{
+				{ProjectID: 1, Project: "Website", TaskID: 10, Task: "Dev", Hours: [7]float64{1, 0, 0, 0, 0, 0, 0.5}, Total: 1.5},
+			},
+			wantDaily: [7]float64{1, 0, 0, 0, 0, 0, 0.5},
+			wantTotal: 1.5,
+		},
+		{
+			name: "merges entries for the same project and task",
+			entries: []harvest.TimeEntry{
+				entry("2026-10-13", 1, website, dev),
+				entry("2026-10-13", 0.5, website, dev),
+				entry("2026-10-14", 2, website, dev),
+				entry("2026-10-14", 1, website, design),
+			},
+			wantRows: []weekRow{
+				{ProjectID: 1, Project: "Website", TaskID: 11, Task: "Design", Hours: [7]float64{0, 0, 1}, Total: 1},
+				{ProjectID: 1, Project: "Website", TaskID: 10, Task: "Dev", Hours: [7]float64{0, 1.5, 2}, Total: 3.5},
+			},
+			wantDaily: [7]float64{0, 1.5, 3},
+			wantTotal: 4.5,
+		},
+		{
+			name: "sorts by project then task",
+			entries: []harvest.TimeEntry{
+				entry("2026-10-16", 1, website, dev),
+				entry("2026-10-16", 1, internal, dev),
+				entry("2026-10-16", 1, website, design),
+				entry("2026-10-16", 1, internal, design),
+			},
+			wantRows: []weekRow{
+				{ProjectID: 2, Project: "Internal", TaskID: 11, Task: "Design", Hours: [7]float64{0, 0, 0, 0, 1}, Total: 1},
+				{ProjectID: 2, Project: "Internal", TaskID: 10, Task: "Dev", Hours: [7]float64{0, 0, 0, 0, 1}, Total: 1},
+				{ProjectID: 1, Project: "Website", TaskID: 11, Task: "Design", Hours: [7]float64{0, 0, 0, 0, 1}, Total: 1},
+				{ProjectID: 1, Project: "Website", TaskID: 10, Task: "Dev", Hours: [7]float64{0, 0, 0, 0, 1}, Total: 1},
+			},
+			wantDaily: [7]float64{0, 0, 0, 0, 4},
+			wantTotal: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := buildWeek(tt.entries, monday, 8)
+			if sheet.Start != "2026-10-12" || sheet.End != "2026-10-18" || sheet.Days[6] != "2026-10-18" {
+				t.Errorf("week %s to %s, days %v", sheet.Start, sheet.End, sheet.Days)
+			}
+			if sheet.DailyTarget != 8 {
+				t.Errorf("daily target %v, want 8", sheet.DailyTarget)
+			}
+			if !reflect.DeepEqual(sheet.Rows, tt.wantRows) {
+				t.Errorf("rows\n got %+v\nwant %+v", sheet.Rows, tt.wantRows)
+			}
+			if sheet.DailyTotals != tt.wantDaily || sheet.Total != tt.wantTotal {
+				t.Errorf("totals %v = %v, want %v = %v", sheet.DailyTotals, sheet.Total, tt.wantDaily, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// BranchRule overrides the directory defaults while a matching git branch is
+// checked out.
+type BranchRule struct {
+	// Pattern is a glob matched against the whole branch name, where *
+	// doesn't cross a /, for example "feature/ACME-*".
+	Pattern   string `json:"pattern"`
+	ProjectID int64  `json:"project_id,omitempty"`
+	TaskID    int64  `json:"task_id,omitempty"`
+	// Ticket is a regular expression finding the ticket in the branch name,
+	// for example "[A-Z]+-[0-9]+". Its first group is used if it has one,
+	// otherwise the whole match.
+	Ticket string `json:"ticket,omitempty"`
+}
+
+// MatchBranch returns the first rule matching branch and the ticket it finds
+// there, or nil if none match.
+func MatchBranch(rules []BranchRule, branch string) (*BranchRule, string, error) {
+	if branch == "" {
+		return nil, "", nil
+	}
+	for i, rule := range rules {
+		ok, err := path.Match(rule.Pattern, branch)
+		if err != nil {
+			return nil, "", fmt.Errorf("branch rule %q: %w", rule.Pattern, err)
+		}
+		if !ok {
+			continue
+		}
+		if rule.Ticket == "" {
+			return &rules[i], "", nil
+		}
+		re, err := regexp.Compile(rule.Ticket)
+		if err != nil {
+			return nil, "", fmt.Errorf("branch rule %q: ticket: %w", rule.Pattern, err)
+		}
+		var ticket string
+		if m := re.FindStringSubmatch(branch); len(m) > 1 {
+			ticket = m[1]
+		} else if len(m) == 1 {
+			ticket = m[0]
+		}
+		return &rules[i], ticket, nil
+	}
+	return nil, "", nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchBranch(t *testing.T) {
+	rules := []BranchRule{
+		{Pattern: "feature/ACME-*", ProjectID: 1, Ticket: `(?:^|/)([A-Z]+-[0-9]+)`},
+		{Pattern: "fix/*", TaskID: 2, Ticket: `[A-Z]+-[0-9]+`},
+		{Pattern: "release/*", ProjectID: 3},
+		{Pattern: "feature/*", ProjectID: 4, Ticket: `[A-Z]+-[0-9]+`},
+	}
+	tests := []struct {
+		branch     string
+		wantRule   int // index into rules, -1 for none
+		wantTicket string
+	}{
+		{branch: "feature/ACME-12-login", wantRule: 0, wantTicket: "ACME-12"},
+		{branch: "fix/OPS-3-typo", wantRule: 1, wantTicket: "OPS-3"},
+		{branch: "fix/typo", wantRule: 1, wantTicket: ""},
+		{branch: "release/2.0", wantRule: 2},
+		{branch: "feature/search", wantRule: 3},
+		{branch: "feature/a/b", wantRule: -1},
+		{branch: "main", wantRule: -1},
+		{branch: "", wantRule: -1},
+	}
+	for _, tt := range tests {
+		rule, ticket, err := MatchBranch(rules, tt.branch)
+		if err != nil {
+			t.Errorf("MatchBranch(%q): %v", tt.branch, err)
+			continue
+		}
+		switch {
+		case tt.wantRule < 0 && rule != nil:
+			t.Errorf("MatchBranch(%q) matched %q, want no rule", tt.branch, rule.Pattern)
+		case tt.wantRule >= 0 && rule != &rules[tt.wantRule]:
+			t.Errorf("MatchBranch(%q) = %v, want %q", tt.branch, rule, rules[tt.wantRule].Pattern)
+		}
+		if ticket != tt.wantTicket {
+			t.Errorf("MatchBranch(%q) ticket = %q, want %q", tt.branch, ticket, tt.wantTicket)
+		}
+	}
+}
+
+func TestMatchBranchErrors(t *testing.T) {
+	for _, rule := range []BranchRule{
+		{Pattern: "feature/["},
+		{Pattern: "feature/*", Ticket: "(["},
+	} {
+		if _, _, err := MatchBranch([]BranchRule{rule}, "feature/x"); err == nil {
+			t.Errorf("rule %+v: want an error", rule)
+		}
+	}
+}
+
+func TestResolveAppliesBranchRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		branch     string
+		wantProj   int64
+		wantTask   int64
+		wantTicket string
+	}{
+		{name: "matching branch", branch: "feature/ACME-12-login", wantProj: 10, wantTask: 2, wantTicket: "ACME-12"},
+		{name: "other branch", branch: "main", wantProj: 1, wantTask: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveEnv(t)
+			saveGlobal(t, &Global{Profiles: map[string]*Profile{"work": {}}})
+			wd, _ := os.Getwd()
+			if err := os.MkdirAll(filepath.Join(wd, ".git"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(wd, ".git", "HEAD"), []byte("ref: refs/heads/"+tt.branch+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &Config{ProjectID: 1, TaskID: 2, Branches: []BranchRule{
+				{Pattern: "feature/*", ProjectID: 10, Ticket: `[A-Z]+-[0-9]+`},
+			}}
+			if err := cfg.Save(filepath.Join(wd, LocalConfigName)); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := Resolve(context.Background(), Flags{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Branch != tt.branch || r.ProjectID != tt.wantProj || r.TaskID != tt.wantTask || r.Ticket != tt.wantTicket {
+				t.Errorf("got branch %q, project %d, task %d, ticket %q; want %q, %d, %d, %q",
+					r.Branch, r.ProjectID, r.TaskID, r.Ticket, tt.branch, tt.wantProj, tt.wantTask, tt.wantTicket)
+			}
+		})
+	}
+}
@@ -7,24 +7,52 @@ import (
 	"path/filepath"
 )
 
+// Config is the per-directory configuration kept in .harvestcli.json.
 type Config struct {
-	ProjectID          int64  `json:"project_id"`
-	TaskID             int64  `json:"task_id"`
-	HarvestAccountID   string `json:"harvest_account_id"`
-	HarvestAccessToken string `json:"harvest_access_token"`
-	HarvestUserID      string `json:"harvest_user_id"`
+	ProjectID int64 `json:"project_id"`
+	TaskID    int64 `json:"task_id"`
+	// Profile pins the directory to a named profile from the global config.
+	Profile string `json:"profile,omitempty"`
+	// Branches override ProjectID and TaskID and supply a ticket on matching
+	// git branches. The first match wins.
+	Branches []BranchRule `json:"branches,omitempty"`
 }
 
-// DefaultConfigPath returns the default config file path (~/.harvestcli/config.json).
-func DefaultConfigPath() string {
-	return filepath.Join("./", ".harvestcli.json")
+// LocalConfigName is the name of the per-directory config file.
+const LocalConfigName = ".harvestcli.json"
+
+// FindLocal looks for .harvestcli.json in dir and then its parents, stopping
+// at the root of a git work tree or the home directory. If none is found it
+// returns where a new one belongs, in the git root or else in dir, and false.
+func FindLocal(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Join(dir, LocalConfigName), false
+	}
+	home, _ := os.UserHomeDir()
+	for d := dir; ; {
+		path := filepath.Join(d, LocalConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		// .git is a directory in a clone and a file in a worktree.
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return path, false
+		}
+		parent := filepath.Dir(d)
+		if d == home || parent == d {
+			break
+		}
+		d = parent
+	}
+	return filepath.Join(dir, LocalConfigName), false
 }
 
 // GlobalConfigPath returns the global config file path (~/.config/harvest_cli/config.json).
 func GlobalConfigPath() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return filepath.Join("./", ".harvestcli.json")
+		return filepath.Join("./", LocalConfigName)
 	}
 	return filepath.Join(homeDir, ".config", "harvest_cli", "config.json")
 }
@@ -54,22 +82,6 @@ func (c *Config) Save(path string) error {
 	return os.WriteFile(path, data, 0o600)
 }
 
-// LoadGlobal loads the global config from ~/.config/harvest_cli/config.json.
-func LoadGlobal() (*Config, error) {
-	path := GlobalConfigPath()
-	return Load(path)
-}
-
-// SaveGlobal saves the config to the global config path, creating directories as needed.
-func (c *Config) SaveGlobal() error {
-	path := GlobalConfigPath()
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return err
-	}
-	return c.Save(path)
-}
-
 // SetupLogger creates a logger that writes to ~/.config/harvest_cli/debug.log
 func SetupLogger() (*log.Logger, error) {
 	logPath := filepath.Join(filepath.Dir(GlobalConfigPath()), "debug.log")
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindLocal(t *testing.T) {
+	// Each case builds a tree under a temporary HOME. Paths are relative to
+	// it; a trailing slash makes a directory.
+	tests := []struct {
+		name      string
+		files     []string
+		dir       string
+		want      string
+		wantFound bool
+	}{
+		{
+			name:      "in the directory",
+			files:     []string{"proj/.git/", "proj/.harvestcli.json", "proj/sub/.harvestcli.json"},
+			dir:       "proj/sub",
+			want:      "proj/sub/.harvestcli.json",
+			wantFound: true,
+		},
+		{
+			name:      "in a parent",
+			files:     []string{"proj/.git/", "proj/.harvestcli.json", "proj/a/b/"},
+			dir:       "proj/a/b",
+			want:      "proj/.harvestcli.json",
+			wantFound: true,
+		},
+		{
+			name:  "stops at the git root and saves there",
+			files: []string{".harvestcli.json", "proj/.git/", "proj/a/b/"},
+			dir:   "proj/a/b",
+			want:  "proj/.harvestcli.json",
+		},
+		{
+			name:  "git worktree",
+			files: []string{".harvestcli.json", "proj/.git", "proj/a/"},
+			dir:   "proj/a",
+			want:  "proj/.harvestcli.json",
+		},
+		{
+			name:      "in home",
+			files:     []string{"home/.harvestcli.json", "home/notes/a/"},
+			dir:       "home/notes/a",
+			want:      "home/.harvestcli.json",
+			wantFound: true,
+		},
+		{
+			name:  "stops at home and saves in the directory",
+			files: []string{".harvestcli.json", "home/notes/a/"},
+			dir:   "home/notes/a",
+			want:  "home/notes/a/.harvestcli.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("HOME", filepath.Join(root, "home"))
+			for _, f := range tt.files {
+				path := filepath.Join(root, f)
+				if f[len(f)-1] == '/' {
+					if err := os.MkdirAll(path, 0o755); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, found := FindLocal(filepath.Join(root, tt.dir))
+			if want := filepath.Join(root, tt.want); got != want || found != tt.wantFound {
+				t.Errorf("FindLocal = %s, %v; want %s, %v", got, found, want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FakeSecrets is a SecretStore backed by a JSON file of keys to secrets, for
+// tests that exercise the keyring code paths headlessly.
+type FakeSecrets struct {
+	Path string
+}
+
+func (f *FakeSecrets) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Path, err)
+	}
+	return secrets, nil
+}
+
+func (f *FakeSecrets) Get(ctx context.Context, key string) (string, error) {
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("no secret %q in %s", key, f.Path)
+	}
+	return secret, nil
+}
+
+func (f *FakeSecrets) Set(ctx context.Context, key, secret string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = secret
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, data, 0o600)
+}
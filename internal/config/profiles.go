@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultProfileName is the profile a flat, pre-profile config is migrated to.
+const DefaultProfileName = "default"
+
+// Profile holds the credentials and settings for one Harvest account.
+type Profile struct {
+	HarvestAccountID   string `json:"harvest_account_id"`
+	HarvestAccessToken string `json:"harvest_access_token,omitempty"`
+	// HarvestAccessTokenRef points to the access token in a SecretStore,
+	// and is used when HarvestAccessToken is empty.
+	HarvestAccessTokenRef string `json:"harvest_access_token_ref,omitempty"`
+	HarvestUserID         string `json:"harvest_user_id,omitempty"`
+	HarvestBaseURL        string `json:"harvest_base_url,omitempty"`
+	// DailyTargetHours is the number of hours per working day below which
+	// the week view highlights a day. Zero means weekly capacity / 5.
+	DailyTargetHours float64 `json:"daily_target_hours,omitempty"`
+}
+
+// Complete reports whether the profile has credentials.
+func (p *Profile) Complete() bool {
+	return p.HarvestAccountID != "" && p.HarvestAccessToken != ""
+}
+
+// Global is the global configuration in ~/.config/harvest_cli/config.json:
+// a set of named profiles, one of which is the default.
+type Global struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// ProfileNames returns the profile names in order.
+func (g *Global) ProfileNames() []string {
+	names := make([]string, 0, len(g.Profiles))
+	for name := range g.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetProfile stores p under name, making it the default if there is none.
+func (g *Global) SetProfile(name string, p *Profile) {
+	if g.Profiles == nil {
+		g.Profiles = make(map[string]*Profile)
+	}
+	g.Profiles[name] = p
+	if g.DefaultProfile == "" {
+		g.DefaultProfile = name
+	}
+}
+
+// UnknownProfileError describes a profile name that isn't configured.
+func (g *Global) UnknownProfileError(name string) error {
+	if len(g.Profiles) == 0 {
+		return fmt.Errorf("profile %q is not configured; run 'harvest_cli config setup --profile %s'", name, name)
+	}
+	return fmt.Errorf("profile %q is not configured (have: %s); run 'harvest_cli config setup --profile %s'",
+		name, strings.Join(g.ProfileNames(), ", "), name)
+}
+
+// flatGlobal is the global config format from before profiles.
+type flatGlobal struct {
+	Profile
+	Profiles json.RawMessage `json:"profiles"`
+}
+
+// LoadGlobal loads the global config from ~/.config/harvest_cli/config.json.
+// A config in the old flat format is migrated to a "default" profile and
+// saved, keeping the original as config.json.bak.
+func LoadGlobal() (*Global, error) {
+	path := GlobalConfigPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Global{Profiles: make(map[string]*Profile)}, nil
+		}
+		return nil, err
+	}
+
+	var flat flatGlobal
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return nil, err
+	}
+	if flat.Profiles == nil && flat.HarvestAccountID+flat.HarvestAccessToken != "" {
+		g := &Global{}
+		g.SetProfile(DefaultProfileName, &flat.Profile)
+		if err := os.WriteFile(path+".bak", data, 0o600); err != nil {
+			return nil, fmt.Errorf("backing up config before migrating to profiles: %w", err)
+		}
+		if err := g.Save(); err != nil {
+			return nil, fmt.Errorf("migrating config to profiles: %w", err)
+		}
+		return g, nil
+	}
+
+	var g Global
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	if g.Profiles == nil {
+		g.Profiles = make(map[string]*Profile)
+	}
+	return &g, nil
+}
+
+// Save writes the global config, creating directories as needed.
+func (g *Global) Save() error {
+	path := GlobalConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
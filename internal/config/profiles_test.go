@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeGlobal points HOME at a temporary directory and writes the global
+// config there, returning its path.
+func writeGlobal(t *testing.T, data string) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	path := GlobalConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadGlobalMigratesFlatConfig(t *testing.T) {
+	flat := `{"harvest_account_id":"123","harvest_access_token":"secret","harvest_user_id":"42"}`
+	path := writeGlobal(t, flat)
+
+	g, err := LoadGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.DefaultProfile != DefaultProfileName {
+		t.Errorf("DefaultProfile = %q, want %q", g.DefaultProfile, DefaultProfileName)
+	}
+	p := g.Profiles[DefaultProfileName]
+	if p == nil || p.HarvestAccountID != "123" || p.HarvestAccessToken != "secret" || p.HarvestUserID != "42" {
+		t.Fatalf("migrated profile = %+v", p)
+	}
+
+	if bak, err := os.ReadFile(path + ".bak"); err != nil || string(bak) != flat {
+		t.Errorf("backup = %q, %v; want the original config", bak, err)
+	}
+	var saved map[string]json.RawMessage
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &saved); err != nil || saved["profiles"] == nil || saved["harvest_account_id"] != nil {
+		t.Errorf("saved config is not in profile format: %s", data)
+	}
+
+	// Loading again reads the migrated config and leaves the backup alone.
+	os.Remove(path + ".bak")
+	again, err := LoadGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Profiles[DefaultProfileName].HarvestAccessToken != "secret" {
+		t.Errorf("reloaded profiles = %v", again.Profiles)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("migrated again: %v", err)
+	}
+}
+
+func TestLoadGlobal(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		g, err := LoadGlobal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Profiles == nil || len(g.Profiles) != 0 {
+			t.Errorf("Profiles = %v, want empty", g.Profiles)
+		}
+		if _, err := os.Stat(GlobalConfigPath()); !os.IsNotExist(err) {
+			t.Errorf("config was created: %v", err)
+		}
+	})
+	t.Run("profiles", func(t *testing.T) {
+		path := writeGlobal(t, `{"default_profile":"work","profiles":{"work":{"harvest_account_id":"1"},"home":{"harvest_account_id":"2"}}}`)
+		g, err := LoadGlobal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.DefaultProfile != "work" || strings.Join(g.ProfileNames(), ",") != "home,work" {
+			t.Errorf("got default %q, profiles %v", g.DefaultProfile, g.ProfileNames())
+		}
+		if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+			t.Errorf("profile config was backed up: %v", err)
+		}
+	})
+	t.Run("malformed", func(t *testing.T) {
+		writeGlobal(t, `{"profiles":`)
+		if _, err := LoadGlobal(); err == nil {
+			t.Error("want an error")
+		}
+	})
+}
+
+func TestSetProfile(t *testing.T) {
+	var g Global
+	g.SetProfile("work", &Profile{HarvestAccountID: "1"})
+	g.SetProfile("home", &Profile{HarvestAccountID: "2"})
+	if g.DefaultProfile != "work" {
+		t.Errorf("DefaultProfile = %q, want the first profile", g.DefaultProfile)
+	}
+	if err := g.UnknownProfileError("play"); !strings.Contains(err.Error(), "have: home, work") {
+		t.Errorf("UnknownProfileError = %v", err)
+	}
+}
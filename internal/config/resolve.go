@@ -0,0 +1,290 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/example/harvestcli/internal/git"
+)
+
+// Source is the layer a setting was resolved from. Later layers win.
+type Source int
+
+const (
+	SourceDefault Source = iota
+	SourceGlobal
+	SourceLocal
+	SourceEnv
+	SourceFlag
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceGlobal:
+		return "global"
+	case SourceLocal:
+		return "local"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	}
+	return "default"
+}
+
+// Setting is one effective configuration value and where it came from.
+type Setting struct {
+	Name  string
+	Value string
+	// Source is the layer the value came from and Origin the flag,
+	// environment variable, file or profile within it.
+	Source Source
+	Origin string
+	// Secret values should be shown masked.
+	Secret bool
+}
+
+// Flags are the command-line values that take part in resolution.
+type Flags struct {
+	Profile string
+	// LocalPath is the directory config to use; when empty it is found
+	// with FindLocal from the working directory.
+	LocalPath   string
+	IgnoreLocal bool
+	// SkipSecrets leaves tokens in secret stores unread, for setup.
+	SkipSecrets bool
+}
+
+// Resolved is the effective configuration, built from command-line flags,
+// environment variables, the directory's .harvestcli.json, the global config
+// and defaults, in that order of precedence.
+type Resolved struct {
+	Global *Global
+	Local  *Config
+	// LocalPath is the directory config that was loaded, or where a new one
+	// would be saved if LocalFound is false.
+	LocalPath   string
+	LocalFound  bool
+	ProfileName string
+	// Branch is the checked out git branch, and BranchRule the rule from
+	// Local matching it, if any.
+	Branch     string
+	BranchRule *BranchRule
+	// ProjectID, TaskID and Ticket are the defaults for new entries, from
+	// BranchRule where it sets them and otherwise from Local.
+	ProjectID int64
+	TaskID    int64
+	Ticket    string
+	// Profile is the selected profile with environment overrides applied.
+	// It is a copy: use CacheUserID to persist a looked up user ID.
+	Profile  *Profile
+	Settings []Setting
+}
+
+// Resolve loads the local and global configs and layers flags and the
+// HARVEST_PROFILE, HARVEST_ACCOUNT_ID, HARVEST_ACCESS_TOKEN, HARVEST_USER_ID
+// and HARVEST_BASE_URL environment variables over them. An access token kept
+// in a secret store is read from it unless the environment provides one.
+func Resolve(ctx context.Context, flags Flags) (*Resolved, error) {
+	global, err := LoadGlobal()
+	if err != nil {
+		return nil, fmt.Errorf("loading global config: %w", err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	r := &Resolved{Global: global, Local: &Config{}, LocalPath: flags.LocalPath}
+	if r.LocalPath == "" {
+		r.LocalPath, r.LocalFound = FindLocal(dir)
+	} else if _, err := os.Stat(r.LocalPath); err == nil {
+		r.LocalFound = true
+	}
+	if !flags.IgnoreLocal && r.LocalFound {
+		if r.Local, err = Load(r.LocalPath); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", r.LocalPath, err)
+		}
+	}
+
+	localSetting := Setting{Name: "local_config", Value: r.LocalPath + " (not created yet)"}
+	switch {
+	case flags.IgnoreLocal:
+		localSetting.Value = "(ignored)"
+		localSetting.Source, localSetting.Origin = SourceFlag, "-i"
+	case r.LocalFound && flags.LocalPath != "":
+		localSetting.Value, localSetting.Source, localSetting.Origin = r.LocalPath, SourceFlag, "-c"
+	case r.LocalFound:
+		localSetting.Value, localSetting.Source = r.LocalPath, SourceLocal
+	}
+	r.Settings = append(r.Settings, localSetting)
+	r.Settings = append(r.Settings, r.selectProfile(flags))
+	stored := global.Profiles[r.ProfileName]
+	r.Profile = &Profile{}
+	if stored != nil {
+		*r.Profile = *stored
+	}
+	inProfile := "profile " + r.ProfileName
+
+	r.layer("account_id", &r.Profile.HarvestAccountID, "HARVEST_ACCOUNT_ID", inProfile, false)
+	tokenOrigin := inProfile
+	if ref := r.Profile.HarvestAccessTokenRef; r.Profile.HarvestAccessToken == "" && ref != "" && !flags.SkipSecrets && os.Getenv("HARVEST_ACCESS_TOKEN") == "" {
+		token, err := ReadSecret(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("reading access token for profile %s: %w", r.ProfileName, err)
+		}
+		r.Profile.HarvestAccessToken = token
+		tokenOrigin += ", " + ref
+	}
+	r.layer("access_token", &r.Profile.HarvestAccessToken, "HARVEST_ACCESS_TOKEN", tokenOrigin, true)
+	// The cached user ID belongs to the stored credentials, so it is only
+	// used with them; see CacheUserID.
+	if r.Setting("account_id").Source != SourceGlobal || r.Setting("access_token").Source != SourceGlobal {
+		r.Profile.HarvestUserID = ""
+	}
+	r.layer("user_id", &r.Profile.HarvestUserID, "HARVEST_USER_ID", inProfile, false)
+	r.layer("base_url", &r.Profile.HarvestBaseURL, "HARVEST_BASE_URL", inProfile, false)
+
+	target := Setting{Name: "daily_target_hours"}
+	if r.Profile.DailyTargetHours != 0 {
+		target.Value = strconv.FormatFloat(r.Profile.DailyTargetHours, 'f', -1, 64)
+		target.Source, target.Origin = SourceGlobal, inProfile
+	}
+	r.Settings = append(r.Settings, target)
+
+	if err := r.applyBranch(dir); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// applyBranch sets the entry defaults, letting a rule matching the branch
+// checked out in dir override the directory's.
+func (r *Resolved) applyBranch(dir string) error {
+	r.ProjectID, r.TaskID = r.Local.ProjectID, r.Local.TaskID
+	branch := Setting{Name: "branch"}
+	name, err := git.Branch(dir)
+	if err != nil && len(r.Local.Branches) > 0 && !errors.Is(err, git.ErrNotRepository) {
+		return fmt.Errorf("reading git branch: %w", err)
+	}
+	if name != "" {
+		r.Branch = name
+		branch.Value, branch.Source, branch.Origin = name, SourceLocal, ".git/HEAD"
+	}
+	r.BranchRule, r.Ticket, err = MatchBranch(r.Local.Branches, r.Branch)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.LocalPath, err)
+	}
+	r.Settings = append(r.Settings, branch)
+
+	project := Setting{Name: "project_id"}
+	task := Setting{Name: "task_id"}
+	ticket := Setting{Name: "ticket", Value: r.Ticket}
+	if r.ProjectID != 0 {
+		project.Source, project.Origin = SourceLocal, r.LocalPath
+	}
+	if r.TaskID != 0 {
+		task.Source, task.Origin = SourceLocal, r.LocalPath
+	}
+	if rule := r.BranchRule; rule != nil {
+		origin := r.LocalPath + ", branch rule " + rule.Pattern
+		if rule.ProjectID != 0 {
+			r.ProjectID = rule.ProjectID
+			project.Source, project.Origin = SourceLocal, origin
+		}
+		if rule.TaskID != 0 {
+			r.TaskID = rule.TaskID
+			task.Source, task.Origin = SourceLocal, origin
+		}
+		if r.Ticket != "" {
+			ticket.Source, ticket.Origin = SourceLocal, origin
+		}
+	}
+	if r.ProjectID != 0 {
+		project.Value = strconv.FormatInt(r.ProjectID, 10)
+	}
+	if r.TaskID != 0 {
+		task.Value = strconv.FormatInt(r.TaskID, 10)
+	}
+	r.Settings = append(r.Settings, project, task, ticket)
+	return nil
+}
+
+// selectProfile picks the profile name from the first of the --profile flag,
+// HARVEST_PROFILE, the local pin and the global default. With none of those
+// and a single profile, that one is used.
+func (r *Resolved) selectProfile(flags Flags) Setting {
+	s := Setting{Name: "profile"}
+	switch {
+	case flags.Profile != "":
+		s.Value, s.Source, s.Origin = flags.Profile, SourceFlag, "--profile"
+	case os.Getenv("HARVEST_PROFILE") != "":
+		s.Value, s.Source, s.Origin = os.Getenv("HARVEST_PROFILE"), SourceEnv, "HARVEST_PROFILE"
+	case r.Local.Profile != "":
+		s.Value, s.Source, s.Origin = r.Local.Profile, SourceLocal, r.LocalPath
+	case r.Global.DefaultProfile != "":
+		s.Value, s.Source, s.Origin = r.Global.DefaultProfile, SourceGlobal, "default_profile"
+	case len(r.Global.Profiles) == 1:
+		s.Value, s.Source, s.Origin = r.Global.ProfileNames()[0], SourceGlobal, "only profile"
+	default:
+		s.Value = DefaultProfileName
+	}
+	r.ProfileName = s.Value
+	return s
+}
+
+// layer records a profile field, replacing it with the environment variable
+// env when that is set.
+func (r *Resolved) layer(name string, field *string, env, origin string, secret bool) {
+	s := Setting{Name: name, Secret: secret}
+	if v := os.Getenv(env); v != "" {
+		*field = v
+		s.Source, s.Origin = SourceEnv, env
+	} else if *field != "" {
+		s.Source, s.Origin = SourceGlobal, origin
+	}
+	s.Value = *field
+	r.Settings = append(r.Settings, s)
+}
+
+// Setting returns the named setting, or a zero Setting if there is none.
+func (r *Resolved) Setting(name string) Setting {
+	for _, s := range r.Settings {
+		if s.Name == name {
+			return s
+		}
+	}
+	return Setting{Name: name}
+}
+
+// Stored returns the selected profile as saved in the global config, or nil
+// if it doesn't exist there.
+func (r *Resolved) Stored() *Profile {
+	return r.Global.Profiles[r.ProfileName]
+}
+
+// CacheUserID records the user ID looked up for the selected credentials. It
+// is saved to the global config only when those credentials came from it.
+func (r *Resolved) CacheUserID(id string) error {
+	r.Profile.HarvestUserID = id
+	stored := r.Stored()
+	if stored == nil || r.Setting("account_id").Source != SourceGlobal || r.Setting("access_token").Source != SourceGlobal {
+		return nil
+	}
+	stored.HarvestUserID = id
+	return r.Global.Save()
+}
+
+// Mask hides all but the last four characters of a secret, or all of it if
+// it is short.
+func Mask(secret string) string {
+	switch {
+	case secret == "":
+		return ""
+	case len(secret) < 12:
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
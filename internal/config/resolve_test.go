@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resolveEnv clears the environment variables Resolve reads and runs from an
+// empty directory outside any git work tree.
+func resolveEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{"HARVEST_PROFILE", "HARVEST_ACCOUNT_ID", "HARVEST_ACCESS_TOKEN", "HARVEST_USER_ID", "HARVEST_BASE_URL"} {
+		t.Setenv(env, "")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// saveGlobal writes g as the global config under a temporary HOME.
+func saveGlobal(t *testing.T, g *Global) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	if err := g.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeLocal writes cfg to a .harvestcli.json in a temporary directory.
+func writeLocal(t *testing.T, cfg *Config) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), LocalConfigName)
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveProfileOrder(t *testing.T) {
+	three := map[string]*Profile{"home": {}, "work": {}, "play": {}}
+	tests := []struct {
+		name       string
+		global     *Global
+		pin        string
+		env        string
+		flag       string
+		want       string
+		wantSource Source
+	}{
+		{name: "default_profile", global: &Global{DefaultProfile: "home", Profiles: three}, want: "home", wantSource: SourceGlobal},
+		{name: "local pin over default", global: &Global{DefaultProfile: "home", Profiles: three}, pin: "play", want: "play", wantSource: SourceLocal},
+		{name: "env over local pin", global: &Global{DefaultProfile: "home", Profiles: three}, pin: "play", env: "work", want: "work", wantSource: SourceEnv},
+		{name: "flag over env", global: &Global{DefaultProfile: "home", Profiles: three}, pin: "play", env: "work", flag: "home", want: "home", wantSource: SourceFlag},
+		{name: "only profile", global: &Global{Profiles: map[string]*Profile{"work": {}}}, want: "work", wantSource: SourceGlobal},
+		{name: "nothing configured", global: &Global{Profiles: three}, want: DefaultProfileName, wantSource: SourceDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveEnv(t)
+			saveGlobal(t, tt.global)
+			t.Setenv("HARVEST_PROFILE", tt.env)
+			local := writeLocal(t, &Config{Profile: tt.pin})
+
+			r, err := Resolve(context.Background(), Flags{Profile: tt.flag, LocalPath: local})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := r.Setting("profile")
+			if r.ProfileName != tt.want || s.Value != tt.want || s.Source != tt.wantSource {
+				t.Errorf("profile = %q (setting %+v), want %q from %s", r.ProfileName, s, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestResolveEnvOverridesGlobal(t *testing.T) {
+	resolveEnv(t)
+	saveGlobal(t, &Global{DefaultProfile: "work", Profiles: map[string]*Profile{
+		"work": {HarvestAccountID: "1", HarvestAccessToken: "global-token", HarvestUserID: "7", HarvestBaseURL: "https://global.example"},
+	}})
+	t.Setenv("HARVEST_ACCOUNT_ID", "2")
+	t.Setenv("HARVEST_ACCESS_TOKEN", "env-token")
+
+	r, err := Resolve(context.Background(), Flags{IgnoreLocal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Profile{HarvestAccountID: "2", HarvestAccessToken: "env-token", HarvestBaseURL: "https://global.example"}
+	if *r.Profile != want {
+		t.Errorf("Profile = %+v, want %+v", *r.Profile, want)
+	}
+	for name, source := range map[string]Source{"account_id": SourceEnv, "access_token": SourceEnv, "user_id": SourceDefault, "base_url": SourceGlobal} {
+		if s := r.Setting(name); s.Source != source {
+			t.Errorf("%s from %s (%s), want %s", name, s.Source, s.Origin, source)
+		}
+	}
+	if stored := r.Stored(); stored.HarvestAccountID != "1" || stored.HarvestAccessToken != "global-token" {
+		t.Errorf("stored profile changed: %+v", stored)
+	}
+	if s := r.Setting("local_config"); s.Value != "(ignored)" {
+		t.Errorf("local_config = %q, want (ignored)", s.Value)
+	}
+}
+
+func TestResolveUserID(t *testing.T) {
+	tests := []struct {
+		name       string
+		envAccount string
+		envToken   string
+		envUser    string
+		want       string
+		wantSource Source
+	}{
+		{name: "cached for the global credentials", want: "7", wantSource: SourceGlobal},
+		{name: "account from the environment", envAccount: "2", wantSource: SourceDefault},
+		{name: "token from the environment", envToken: "env-token", wantSource: SourceDefault},
+		{name: "user from the environment", envToken: "env-token", envUser: "9", want: "9", wantSource: SourceEnv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveEnv(t)
+			saveGlobal(t, &Global{DefaultProfile: "work", Profiles: map[string]*Profile{
+				"work": {HarvestAccountID: "1", HarvestAccessToken: "global-token", HarvestUserID: "7"},
+			}})
+			t.Setenv("HARVEST_ACCOUNT_ID", tt.envAccount)
+			t.Setenv("HARVEST_ACCESS_TOKEN", tt.envToken)
+			t.Setenv("HARVEST_USER_ID", tt.envUser)
+
+			r, err := Resolve(context.Background(), Flags{IgnoreLocal: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := r.Setting("user_id"); r.Profile.HarvestUserID != tt.want || s.Source != tt.wantSource {
+				t.Errorf("user ID = %q from %s, want %q from %s", r.Profile.HarvestUserID, s.Source, tt.want, tt.wantSource)
+			}
+			if stored := r.Stored(); stored.HarvestUserID != "7" {
+				t.Errorf("stored user ID changed to %q", stored.HarvestUserID)
+			}
+		})
+	}
+}
+
+func TestCacheUserID(t *testing.T) {
+	tests := []struct {
+		name      string
+		envToken  string
+		wantSaved string
+	}{
+		{name: "credentials from the global config", wantSaved: "42"},
+		{name: "token from the environment", envToken: "env-token", wantSaved: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveEnv(t)
+			saveGlobal(t, &Global{DefaultProfile: "work", Profiles: map[string]*Profile{
+				"work": {HarvestAccountID: "1", HarvestAccessToken: "global-token"},
+			}})
+			t.Setenv("HARVEST_ACCESS_TOKEN", tt.envToken)
+
+			r, err := Resolve(context.Background(), Flags{IgnoreLocal: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := r.CacheUserID("42"); err != nil {
+				t.Fatal(err)
+			}
+			if r.Profile.HarvestUserID != "42" {
+				t.Errorf("Profile.HarvestUserID = %q, want 42", r.Profile.HarvestUserID)
+			}
+			g, err := LoadGlobal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := g.Profiles["work"].HarvestUserID; got != tt.wantSaved {
+				t.Errorf("saved user ID = %q, want %q", got, tt.wantSaved)
+			}
+		})
+	}
+}
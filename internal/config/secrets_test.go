@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useFakeSecrets registers a FakeSecrets in a temporary directory as the
+// "fake" store for the rest of the test.
+func useFakeSecrets(t *testing.T) *FakeSecrets {
+	t.Helper()
+	fake := &FakeSecrets{Path: filepath.Join(t.TempDir(), "secrets.json")}
+	secretStores["fake"] = fake
+	t.Cleanup(func() { delete(secretStores, "fake") })
+	return fake
+}
+
+func TestFakeSecrets(t *testing.T) {
+	ctx := context.Background()
+	fake := &FakeSecrets{Path: filepath.Join(t.TempDir(), "nested", "secrets.json")}
+	if _, err := fake.Get(ctx, "work"); err == nil {
+		t.Error("Get of a missing key succeeded")
+	}
+	if err := fake.Set(ctx, "work", "one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.Set(ctx, "home", "two"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"work": "one", "home": "two"} {
+		if got, err := fake.Get(ctx, key); err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+	if fi, err := os.Stat(fake.Path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("secrets file mode = %v, %v; want 0600", fi.Mode(), err)
+	}
+}
+
+func TestSecretRefs(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakeSecrets(t)
+
+	ref, err := WriteSecret(ctx, "fake", "harvest_cli/work", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "fake:harvest_cli/work" {
+		t.Errorf("ref = %q", ref)
+	}
+	if got, err := ReadSecret(ctx, ref); err != nil || got != "s3cret" {
+		t.Errorf("ReadSecret(%q) = %q, %v", ref, got, err)
+	}
+	if err := fake.Set(ctx, "blank", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{"no-colon", "fake:", "vault:work", "fake:missing", "fake:blank"} {
+		if got, err := ReadSecret(ctx, ref); err == nil {
+			t.Errorf("ReadSecret(%q) = %q, want an error", ref, got)
+		}
+	}
+	if _, err := WriteSecret(ctx, "command", "echo hi", "s3cret"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("WriteSecret to command = %v, want ErrReadOnly", err)
+	}
+	if _, err := WriteSecret(ctx, "vault", "work", "s3cret"); err == nil {
+		t.Error("WriteSecret to an unknown store succeeded")
+	}
+}
+
+func TestSecretFileRefs(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(GlobalConfigPath()), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	key := SecretKey("file", "work")
+	if want := filepath.Join(filepath.Dir(GlobalConfigPath()), "work.token"); key != want {
+		t.Errorf("SecretKey = %q, want %q", key, want)
+	}
+	ref, err := WriteSecret(ctx, "file", key, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadSecret(ctx, ref); err != nil || got != "s3cret" {
+		t.Errorf("ReadSecret(%q) = %q, %v", ref, got, err)
+	}
+	if got, err := ReadSecret(ctx, "command:printf 'from-command\\n'"); err != nil || got != "from-command" {
+		t.Errorf("command ref = %q, %v", got, err)
+	}
+}
+
+func TestSecretStoreNamed(t *testing.T) {
+	for _, name := range []string{"secret-tool", "pass", "command", "file"} {
+		if _, err := SecretStoreNamed(name); err != nil {
+			t.Errorf("SecretStoreNamed(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"fake", PlainStore, ""} {
+		if _, err := SecretStoreNamed(name); err == nil {
+			t.Errorf("SecretStoreNamed(%q) succeeded", name)
+		}
+	}
+}
+
+func TestResolveReadsTokenRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		envToken  string
+		flags     Flags
+		wantToken string
+	}{
+		{name: "read from the store", wantToken: "from-store"},
+		{name: "environment wins", envToken: "from-env", wantToken: "from-env"},
+		{name: "skipped for setup", flags: Flags{SkipSecrets: true}, wantToken: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveEnv(t)
+			saveGlobal(t, &Global{DefaultProfile: "work", Profiles: map[string]*Profile{
+				"work": {HarvestAccountID: "1", HarvestAccessTokenRef: "fake:harvest_cli/work"},
+			}})
+			fake := useFakeSecrets(t)
+			if tt.wantToken == "from-store" {
+				if err := fake.Set(context.Background(), "harvest_cli/work", "from-store"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Otherwise the key is missing, so reading it would fail.
+			t.Setenv("HARVEST_ACCESS_TOKEN", tt.envToken)
+
+			tt.flags.IgnoreLocal = true
+			r, err := Resolve(context.Background(), tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Profile.HarvestAccessToken != tt.wantToken {
+				t.Errorf("token = %q, want %q", r.Profile.HarvestAccessToken, tt.wantToken)
+			}
+			if s := r.Setting("access_token"); tt.wantToken == "from-store" && !strings.Contains(s.Origin, "fake:harvest_cli/work") {
+				t.Errorf("access_token origin = %q, want the ref", s.Origin)
+			}
+			if stored := r.Stored(); stored.HarvestAccessToken != "" {
+				t.Errorf("token copied into the stored profile: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestResolveFailsOnUnreadableRef(t *testing.T) {
+	resolveEnv(t)
+	saveGlobal(t, &Global{DefaultProfile: "work", Profiles: map[string]*Profile{
+		"work": {HarvestAccountID: "1", HarvestAccessTokenRef: "fake:harvest_cli/work"},
+	}})
+	useFakeSecrets(t)
+	if _, err := Resolve(context.Background(), Flags{IgnoreLocal: true}); err == nil || !strings.Contains(err.Error(), "profile work") {
+		t.Errorf("got %v, want an error naming the profile", err)
+	}
+}
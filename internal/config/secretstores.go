@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SecretTool keeps secrets in the Secret Service keyring (GNOME Keyring,
+// KWallet) over D-Bus with the secret-tool command.
+type SecretTool struct{}
+
+func (SecretTool) Get(ctx context.Context, key string) (string, error) {
+	out, err := exec.CommandContext(ctx, "secret-tool", "lookup", "service", "harvest_cli", "key", key).Output()
+	if err != nil {
+		return "", commandError("secret-tool", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (SecretTool) Set(ctx context.Context, key, secret string) error {
+	cmd := exec.CommandContext(ctx, "secret-tool", "store", "--label", "Harvest CLI access token ("+key+")",
+		"service", "harvest_cli", "key", key)
+	cmd.Stdin = strings.NewReader(secret)
+	return commandError("secret-tool", run(cmd))
+}
+
+// Pass keeps secrets in the pass password store. Only the first line of an
+// entry is read, as is conventional.
+type Pass struct{}
+
+func (Pass) Get(ctx context.Context, key string) (string, error) {
+	out, err := exec.CommandContext(ctx, "pass", "show", key).Output()
+	if err != nil {
+		return "", commandError("pass", err)
+	}
+	first, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimSpace(first), nil
+}
+
+func (Pass) Set(ctx context.Context, key, secret string) error {
+	cmd := exec.CommandContext(ctx, "pass", "insert", "--multiline", "--force", key)
+	cmd.Stdin = strings.NewReader(secret + "\n")
+	return commandError("pass", run(cmd))
+}
+
+// SecretCommand runs the key with sh -c and reads the secret from its
+// output, for password managers with their own CLI such as `op read`.
+type SecretCommand struct{}
+
+func (SecretCommand) Get(ctx context.Context, key string) (string, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", key).Output()
+	if err != nil {
+		return "", commandError(key, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (SecretCommand) Set(ctx context.Context, key, secret string) error {
+	return ErrReadOnly
+}
+
+// SecretFile keeps a secret in a plaintext file readable only by the user;
+// the key is the file's path.
+type SecretFile struct{}
+
+func (SecretFile) Get(ctx context.Context, key string) (string, error) {
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (SecretFile) Set(ctx context.Context, key, secret string) error {
+	return os.WriteFile(key, []byte(secret+"\n"), 0o600)
+}
+
+// run runs cmd, keeping its stderr for the error.
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// commandError adds the command name and, for Output, its stderr to err.
+func commandError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
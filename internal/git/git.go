@@ -0,0 +1,68 @@
+// Package git reads the state of a git work tree straight from its files, so
+// the git binary isn't needed.
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository is returned when a directory isn't inside a work tree.
+var ErrNotRepository = errors.New("not in a git repository")
+
+// Dir returns the git directory of the work tree containing dir. In a linked
+// worktree or a submodule .git is a file pointing at it.
+func Dir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ".git")
+		info, err := os.Stat(path)
+		if err == nil {
+			if info.IsDir() {
+				return path, nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if !ok {
+				return "", fmt.Errorf("%s: missing gitdir line", path)
+			}
+			gitDir = strings.TrimSpace(gitDir)
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return gitDir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+// Branch returns the name of the branch checked out in the work tree
+// containing dir, such as "feature/ACME-12", or "" if HEAD is detached.
+func Branch(dir string) (string, error) {
+	gitDir, err := Dir(dir)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref:")
+	if !ok {
+		return "", nil
+	}
+	return strings.TrimPrefix(strings.TrimSpace(ref), "refs/heads/"), nil
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func write(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBranch(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, root string) string // returns the directory to look from
+		want  string
+	}{
+		{
+			name: "clone",
+			setup: func(t *testing.T, root string) string {
+				write(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/feature/ACME-12-login\n")
+				return root
+			},
+			want: "feature/ACME-12-login",
+		},
+		{
+			name: "subdirectory",
+			setup: func(t *testing.T, root string) string {
+				write(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+				sub := filepath.Join(root, "a", "b")
+				if err := os.MkdirAll(sub, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				return sub
+			},
+			want: "main",
+		},
+		{
+			name: "detached HEAD",
+			setup: func(t *testing.T, root string) string {
+				write(t, filepath.Join(root, ".git", "HEAD"), "0123456789abcdef0123456789abcdef01234567\n")
+				return root
+			},
+			want: "",
+		},
+		{
+			name: "worktree with an absolute gitdir",
+			setup: func(t *testing.T, root string) string {
+				gitDir := filepath.Join(root, "main", ".git", "worktrees", "fix")
+				write(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/fix/ACME-7\n")
+				write(t, filepath.Join(root, "fix", ".git"), "gitdir: "+gitDir+"\n")
+				return filepath.Join(root, "fix")
+			},
+			want: "fix/ACME-7",
+		},
+		{
+			name: "worktree with a relative gitdir",
+			setup: func(t *testing.T, root string) string {
+				write(t, filepath.Join(root, "main", ".git", "worktrees", "fix", "HEAD"), "ref: refs/heads/fix/ACME-8\n")
+				write(t, filepath.Join(root, "fix", ".git"), "gitdir: ../main/.git/worktrees/fix\n")
+				sub := filepath.Join(root, "fix", "src")
+				if err := os.MkdirAll(sub, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				return sub
+			},
+			want: "fix/ACME-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tt.setup(t, t.TempDir())
+			got, err := Branch(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Branch = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchErrors(t *testing.T) {
+	t.Run("not a repository", func(t *testing.T) {
+		if _, err := Branch(t.TempDir()); !errors.Is(err, ErrNotRepository) {
+			t.Errorf("got %v, want ErrNotRepository", err)
+		}
+	})
+	t.Run("malformed .git file", func(t *testing.T) {
+		root := t.TempDir()
+		write(t, filepath.Join(root, ".git"), "not a pointer\n")
+		if _, err := Branch(root); err == nil || errors.Is(err, ErrNotRepository) {
+			t.Errorf("got %v, want a gitdir error", err)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package harvest
+
+import "context"
+
+// API is the set of Harvest operations the CLI uses. *Client implements it,
+// and harvesttest.Mock provides a hand-written fake for tests.
+type API interface {
+	Me(ctx context.Context) (*UserProfile, error)
+	Company(ctx context.Context) (*Company, error)
+	ListProjects(ctx context.Context) ([]Project, error)
+	ListTasks(ctx context.Context, projectID int64) ([]Task, error)
+	ListMyProjectAssignments(ctx context.Context) ([]ProjectAssignment, error)
+	CreateTimeEntry(ctx context.Context, entry TimeEntryRequest) (*TimeEntryResponse, error)
+	ListTimeEntries(ctx context.Context, filter TimeEntryFilter) ([]TimeEntry, error)
+	GetTimeEntry(ctx context.Context, timeEntryID int64) (*TimeEntry, error)
+	RestartTimeEntry(ctx context.Context, timeEntryID int64) (*TimeEntry, error)
+	StopTimeEntry(ctx context.Context, timeEntryID int64) (*TimeEntry, error)
+	UpdateTimeEntry(ctx context.Context, timeEntryID int64, update TimeEntryUpdateRequest) (*TimeEntry, error)
+	DeleteTimeEntry(ctx context.Context, timeEntryID int64) error
+	ListInvoices(ctx context.Context, from, to *string) ([]InvoiceDetail, error)
+	ListExpenses(ctx context.Context, from, to *string) ([]ExpenseDetail, error)
+	ListExpenseCategories(ctx context.Context) ([]ExpenseCategory, error)
+	CreateExpense(ctx context.Context, reqBody ExpenseCreateRequest) (*ExpenseDetail, error)
+	CreateExpenseWithReceipt(ctx context.Context, reqBody ExpenseCreateRequest, receiptPath string) (*ExpenseDetail, error)
+}
+
+var _ API = (*Client)(nil)
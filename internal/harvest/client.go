@@ -2,35 +2,107 @@ package harvest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
-const baseURL = "https://api.harvestapp.com/v2"
+// DefaultBaseURL is the Harvest v2 API endpoint used unless overridden with WithBaseURL.
+const DefaultBaseURL = "https://api.harvestapp.com/v2"
+
+// DefaultUserAgent identifies the CLI to Harvest, which requires a User-Agent on every request.
+const DefaultUserAgent = "harvest_cli (https://github.com/Paradem/harvest_cli)"
+
+// DefaultTimeout bounds a single HTTP attempt unless overridden with WithTimeout.
+const DefaultTimeout = 30 * time.Second
 
 // Client holds the HTTP client and auth info.
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	userAgent  string
 	accountID  string
 	token      string
+	timeout    time.Duration
+	retry      RetryPolicy
+	sleep      func(context.Context, time.Duration) error
+}
+
+// Option configures a Client in NewClient.
+type Option func(*Client)
+
+// WithTimeout sets the timeout for each HTTP attempt, including reading the
+// response body. Zero disables the timeout and relies on the caller's context.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// WithBaseURL points the client at a different API root, e.g. a local stub
+// server. A trailing slash is ignored.
+func WithBaseURL(u string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimRight(u, "/") }
+}
+
+// WithHTTPClient replaces the default http.Client, e.g. to go through a proxy.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// WithTransport sets the RoundTripper used for requests, e.g. a recording
+// transport. The http.Client in use is copied rather than modified.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Transport = rt
+		c.httpClient = &hc
+	}
+}
+
+// WithUserAgent overrides the User-Agent sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithRetryPolicy overrides DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
 }
 
 // NewClient creates a Harvest API client using the provided account ID and access token.
-func NewClient(accountID, accessToken string) (*Client, error) {
+func NewClient(accountID, accessToken string, opts ...Option) (*Client, error) {
 	if accountID == "" || accessToken == "" {
 		return nil, fmt.Errorf("account ID and access token must be provided")
 	}
-	return &Client{httpClient: http.DefaultClient, accountID: accountID, token: accessToken}, nil
+	c := &Client{
+		httpClient: http.DefaultClient,
+		baseURL:    DefaultBaseURL,
+		userAgent:  DefaultUserAgent,
+		accountID:  accountID,
+		token:      accessToken,
+		timeout:    DefaultTimeout,
+		retry:      DefaultRetryPolicy,
+		sleep:      sleepContext,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
-func (c *Client) newRequest(method, path string, body interface{}) (*http.Request, error) {
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
 	var buf io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
@@ -39,26 +111,32 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 		}
 		buf = bytes.NewReader(b)
 	}
-	url := fmt.Sprintf("%s%s", baseURL, path)
-	req, err := http.NewRequest(method, url, buf)
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, buf)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Harvest-Account-ID", c.accountID)
+	c.setHeaders(req)
 	req.Header.Set("Content-Type", "application/json")
 	return req, nil
 }
 
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Harvest-Account-ID", c.accountID)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+}
+
 func (c *Client) do(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("harvest API error %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp)
 	}
 	if v != nil {
 		return json.NewDecoder(resp.Body).Decode(v)
@@ -66,19 +144,60 @@ func (c *Client) do(req *http.Request, v interface{}) error {
 	return nil
 }
 
-// ListProjects fetches all projects.
-func (c *Client) ListProjects() ([]Project, error) {
-	req, err := c.newRequest("GET", "/projects?is_active=true", nil)
+// Me fetches the profile of the user the access token belongs to.
+func (c *Client) Me(ctx context.Context) (*UserProfile, error) {
+	req, err := c.newRequest(ctx, "GET", "/users/me", nil)
 	if err != nil {
 		return nil, err
 	}
-	var res struct {
-		Projects []Project `json:"projects"`
+
+	var res UserProfile
+	if err := c.do(req, &res); err != nil {
+		return nil, err
 	}
+	return &res, nil
+}
+
+// Company fetches the account settings, including whether time is tracked by
+// duration or by start and end time.
+func (c *Client) Company(ctx context.Context) (*Company, error) {
+	req, err := c.newRequest(ctx, "GET", "/company", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Company
 	if err := c.do(req, &res); err != nil {
 		return nil, err
 	}
-	return res.Projects, nil
+	return &res, nil
+}
+
+// ListProjects fetches all active projects, following pagination.
+func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
+	return collect(c.IterProjects(ctx))
+}
+
+// IterProjects streams all active projects page by page.
+func (c *Client) IterProjects(ctx context.Context) iter.Seq2[Project, error] {
+	return paginate[Project](ctx, c, "/projects?is_active=true", "projects")
+}
+
+// ListMyProjectAssignments fetches the current user's active project
+// assignments with their task assignments, following pagination. Unlike
+// ListProjects and ListTasks it does not require an administrator or manager role.
+func (c *Client) ListMyProjectAssignments(ctx context.Context) ([]ProjectAssignment, error) {
+	assignments, err := collect(paginate[ProjectAssignment](ctx, c, "/users/me/project_assignments", "project_assignments"))
+	if err != nil {
+		return nil, err
+	}
+	active := assignments[:0]
+	for _, pa := range assignments {
+		if pa.IsActive {
+			active = append(active, pa)
+		}
+	}
+	return active, nil
 }
 
 func pluckTasks(ts []TaskAssignment) []Task {
@@ -89,27 +208,19 @@ func pluckTasks(ts []TaskAssignment) []Task {
 	return tasks
 }
 
-// ListTasks fetches tasks for a project.
-func (c *Client) ListTasks(projectID int64) ([]Task, error) {
+// ListTasks fetches tasks for a project, following pagination.
+func (c *Client) ListTasks(ctx context.Context, projectID int64) ([]Task, error) {
 	path := fmt.Sprintf("/projects/%d/task_assignments", projectID)
-	req, err := c.newRequest("GET", path, nil)
+	assignments, err := collect(paginate[TaskAssignment](ctx, c, path, "task_assignments"))
 	if err != nil {
 		return nil, err
 	}
-
-	var res struct {
-		TaskAssignments []TaskAssignment `json:"task_assignments"`
-	}
-
-	if err := c.do(req, &res); err != nil {
-		return nil, err
-	}
-	return pluckTasks(res.TaskAssignments), nil
+	return pluckTasks(assignments), nil
 }
 
 // CreateTimeEntry posts a new time entry.
-func (c *Client) CreateTimeEntry(entry TimeEntryRequest) (*TimeEntryResponse, error) {
-	req, err := c.newRequest("POST", "/time_entries", entry)
+func (c *Client) CreateTimeEntry(ctx context.Context, entry TimeEntryRequest) (*TimeEntryResponse, error) {
+	req, err := c.newRequest(ctx, "POST", "/time_entries", entry)
 	if err != nil {
 		return nil, err
 	}
@@ -121,42 +232,68 @@ func (c *Client) CreateTimeEntry(entry TimeEntryRequest) (*TimeEntryResponse, er
 	return &res, nil
 }
 
-// ListTimeEntries fetches time entries with optional date and user filtering.
-func (c *Client) ListTimeEntries(from, to *string, userID *int64) ([]TimeEntry, error) {
+// ListTimeEntries fetches the time entries matching filter, following
+// pagination.
+func (c *Client) ListTimeEntries(ctx context.Context, filter TimeEntryFilter) ([]TimeEntry, error) {
+	return collect(c.IterTimeEntries(ctx, filter))
+}
+
+// IterTimeEntries streams time entries page by page, so large date ranges
+// don't have to be held in memory.
+func (c *Client) IterTimeEntries(ctx context.Context, filter TimeEntryFilter) iter.Seq2[TimeEntry, error] {
 	path := "/time_entries"
-	params := make([]string, 0, 3)
-	if from != nil {
-		params = append(params, "from="+*from)
+	params := make([]string, 0, 8)
+	if filter.From != nil {
+		params = append(params, "from="+*filter.From)
 	}
-	if to != nil {
-		params = append(params, "to="+*to)
+	if filter.To != nil {
+		params = append(params, "to="+*filter.To)
+	}
+	if filter.UserID != nil {
+		params = append(params, fmt.Sprintf("user_id=%d", *filter.UserID))
+	}
+	if filter.ClientID != nil {
+		params = append(params, fmt.Sprintf("client_id=%d", *filter.ClientID))
+	}
+	if filter.ProjectID != nil {
+		params = append(params, fmt.Sprintf("project_id=%d", *filter.ProjectID))
+	}
+	if filter.TaskID != nil {
+		params = append(params, fmt.Sprintf("task_id=%d", *filter.TaskID))
+	}
+	if filter.IsRunning != nil {
+		params = append(params, fmt.Sprintf("is_running=%t", *filter.IsRunning))
 	}
-	if userID != nil {
-		params = append(params, fmt.Sprintf("user_id=%d", *userID))
+	if filter.IsBilled != nil {
+		params = append(params, fmt.Sprintf("is_billed=%t", *filter.IsBilled))
 	}
 
 	if len(params) > 0 {
 		path += "?" + strings.Join(params, "&")
 	}
 
-	req, err := c.newRequest("GET", path, nil)
+	return paginate[TimeEntry](ctx, c, path, "time_entries")
+}
+
+// GetTimeEntry fetches a single time entry.
+func (c *Client) GetTimeEntry(ctx context.Context, timeEntryID int64) (*TimeEntry, error) {
+	path := fmt.Sprintf("/time_entries/%d", timeEntryID)
+	req, err := c.newRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var res struct {
-		TimeEntries []TimeEntry `json:"time_entries"`
-	}
+	var res TimeEntry
 	if err := c.do(req, &res); err != nil {
 		return nil, err
 	}
-	return res.TimeEntries, nil
+	return &res, nil
 }
 
 // RestartTimeEntry restarts a stopped time entry.
-func (c *Client) RestartTimeEntry(timeEntryID int64) (*TimeEntry, error) {
+func (c *Client) RestartTimeEntry(ctx context.Context, timeEntryID int64) (*TimeEntry, error) {
 	path := fmt.Sprintf("/time_entries/%d/restart", timeEntryID)
-	req, err := c.newRequest("PATCH", path, nil)
+	req, err := c.newRequest(ctx, "PATCH", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -169,9 +306,9 @@ func (c *Client) RestartTimeEntry(timeEntryID int64) (*TimeEntry, error) {
 }
 
 // StopTimeEntry stops a running time entry.
-func (c *Client) StopTimeEntry(timeEntryID int64) (*TimeEntry, error) {
+func (c *Client) StopTimeEntry(ctx context.Context, timeEntryID int64) (*TimeEntry, error) {
 	path := fmt.Sprintf("/time_entries/%d/stop", timeEntryID)
-	req, err := c.newRequest("PATCH", path, nil)
+	req, err := c.newRequest(ctx, "PATCH", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -183,11 +320,10 @@ func (c *Client) StopTimeEntry(timeEntryID int64) (*TimeEntry, error) {
 	return &res, nil
 }
 
-// UpdateTimeEntry updates a time entry with new hours.
-func (c *Client) UpdateTimeEntry(timeEntryID int64, hours float64) (*TimeEntry, error) {
+// UpdateTimeEntry changes the fields of a time entry that are set in update.
+func (c *Client) UpdateTimeEntry(ctx context.Context, timeEntryID int64, update TimeEntryUpdateRequest) (*TimeEntry, error) {
 	path := fmt.Sprintf("/time_entries/%d", timeEntryID)
-	updateReq := TimeEntryUpdateRequest{Hours: hours}
-	req, err := c.newRequest("PATCH", path, updateReq)
+	req, err := c.newRequest(ctx, "PATCH", path, update)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +335,24 @@ func (c *Client) UpdateTimeEntry(timeEntryID int64, hours float64) (*TimeEntry,
 	return &res, nil
 }
 
-func (c *Client) ListInvoices(from, to *string) ([]InvoiceDetail, error) {
+// DeleteTimeEntry deletes a time entry. Harvest refuses entries that are
+// locked, approved or invoiced.
+func (c *Client) DeleteTimeEntry(ctx context.Context, timeEntryID int64) error {
+	path := fmt.Sprintf("/time_entries/%d", timeEntryID)
+	req, err := c.newRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+// ListInvoices fetches invoices with optional date filtering, following pagination.
+func (c *Client) ListInvoices(ctx context.Context, from, to *string) ([]InvoiceDetail, error) {
+	return collect(c.IterInvoices(ctx, from, to))
+}
+
+// IterInvoices streams invoices page by page.
+func (c *Client) IterInvoices(ctx context.Context, from, to *string) iter.Seq2[InvoiceDetail, error] {
 	path := "/invoices?per_page=100"
 	if from != nil {
 		path += "&from=" + *from
@@ -207,20 +360,16 @@ func (c *Client) ListInvoices(from, to *string) ([]InvoiceDetail, error) {
 	if to != nil {
 		path += "&to=" + *to
 	}
+	return paginate[InvoiceDetail](ctx, c, path, "invoices")
+}
 
-	req, err := c.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var res InvoiceListResponse
-	if err := c.do(req, &res); err != nil {
-		return nil, err
-	}
-	return res.Invoices, nil
+// ListExpenses fetches expenses with optional date filtering, following pagination.
+func (c *Client) ListExpenses(ctx context.Context, from, to *string) ([]ExpenseDetail, error) {
+	return collect(c.IterExpenses(ctx, from, to))
 }
 
-func (c *Client) ListExpenses(from, to *string) ([]ExpenseDetail, error) {
+// IterExpenses streams expenses page by page.
+func (c *Client) IterExpenses(ctx context.Context, from, to *string) iter.Seq2[ExpenseDetail, error] {
 	path := "/expenses"
 	params := make([]string, 0, 2)
 	if from != nil {
@@ -232,34 +381,16 @@ func (c *Client) ListExpenses(from, to *string) ([]ExpenseDetail, error) {
 	if len(params) > 0 {
 		path += "?" + strings.Join(params, "&")
 	}
-
-	req, err := c.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var res ExpenseListResponse
-	if err := c.do(req, &res); err != nil {
-		return nil, err
-	}
-	return res.Expenses, nil
+	return paginate[ExpenseDetail](ctx, c, path, "expenses")
 }
 
-func (c *Client) ListExpenseCategories() ([]ExpenseCategory, error) {
-	req, err := c.newRequest("GET", "/expense_categories?is_active=true", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var res ExpenseCategoryListResponse
-	if err := c.do(req, &res); err != nil {
-		return nil, err
-	}
-	return res.ExpenseCategories, nil
+// ListExpenseCategories fetches all active expense categories, following pagination.
+func (c *Client) ListExpenseCategories(ctx context.Context) ([]ExpenseCategory, error) {
+	return collect(paginate[ExpenseCategory](ctx, c, "/expense_categories?is_active=true", "expense_categories"))
 }
 
-func (c *Client) CreateExpense(reqBody ExpenseCreateRequest) (*ExpenseDetail, error) {
-	req, err := c.newRequest("POST", "/expenses", reqBody)
+func (c *Client) CreateExpense(ctx context.Context, reqBody ExpenseCreateRequest) (*ExpenseDetail, error) {
+	req, err := c.newRequest(ctx, "POST", "/expenses", reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +402,7 @@ func (c *Client) CreateExpense(reqBody ExpenseCreateRequest) (*ExpenseDetail, er
 	return &res, nil
 }
 
-func (c *Client) CreateExpenseWithReceipt(reqBody ExpenseCreateRequest, receiptPath string) (*ExpenseDetail, error) {
+func (c *Client) CreateExpenseWithReceipt(ctx context.Context, reqBody ExpenseCreateRequest, receiptPath string) (*ExpenseDetail, error) {
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
@@ -302,13 +433,12 @@ func (c *Client) CreateExpenseWithReceipt(reqBody ExpenseCreateRequest, receiptP
 	io.Copy(part, file)
 	writer.Close()
 
-	url := baseURL + "/expenses"
-	req, err := http.NewRequest("POST", url, &buf)
+	url := c.baseURL + "/expenses"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, &buf)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Harvest-Account-ID", c.accountID)
+	c.setHeaders(req)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	var res ExpenseDetail
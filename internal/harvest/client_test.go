@@ -0,0 +1,401 @@
+package harvest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/harvest/harvesttest"
+)
+
+func TestListFollowsPages(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		seed  func(*harvesttest.Server)
+		list  func(*harvest.Client) (int, error)
+		want  int
+		pages int
+	}{
+		{
+			name: "projects",
+			seed: func(s *harvesttest.Server) {
+				for i := range 5 {
+					s.AddProject(harvest.Project{Name: fmt.Sprintf("P%d", i), Active: true})
+				}
+			},
+			list: func(c *harvest.Client) (int, error) {
+				ps, err := c.ListProjects(ctx)
+				return len(ps), err
+			},
+			want:  5,
+			pages: 3,
+		},
+		{
+			name: "project assignments",
+			seed: func(s *harvesttest.Server) {
+				for i := range 4 {
+					s.AddProject(harvest.Project{Name: fmt.Sprintf("P%d", i), Active: true}, harvest.Task{Name: "Dev"})
+				}
+			},
+			list: func(c *harvest.Client) (int, error) {
+				pas, err := c.ListMyProjectAssignments(ctx)
+				return len(pas), err
+			},
+			want:  4,
+			pages: 2,
+		},
+		{
+			name: "time entries",
+			seed: func(s *harvesttest.Server) {
+				for i := range 7 {
+					s.AddTimeEntry(harvest.TimeEntry{SpentDate: fmt.Sprintf("2026-10-%02d", i+1), Hours: 1})
+				}
+			},
+			list: func(c *harvest.Client) (int, error) {
+				es, err := c.ListTimeEntries(ctx, harvest.TimeEntryFilter{})
+				return len(es), err
+			},
+			want:  7,
+			pages: 4,
+		},
+		{
+			// Invoices are requested 100 at a time regardless of PerPage.
+			name: "invoices",
+			seed: func(s *harvesttest.Server) {
+				for i := range 101 {
+					s.AddInvoice(harvest.InvoiceDetail{Number: fmt.Sprintf("INV-%d", i), IssuedAt: "2026-10-01"})
+				}
+			},
+			list: func(c *harvest.Client) (int, error) {
+				is, err := c.ListInvoices(ctx, nil, nil)
+				return len(is), err
+			},
+			want:  101,
+			pages: 2,
+		},
+		{
+			name: "expense categories",
+			seed: func(s *harvesttest.Server) {
+				for i := range 2 {
+					s.AddExpenseCategory(harvest.ExpenseCategory{Name: fmt.Sprintf("C%d", i)})
+				}
+			},
+			list: func(c *harvest.Client) (int, error) {
+				cs, err := c.ListExpenseCategories(ctx)
+				return len(cs), err
+			},
+			want:  2,
+			pages: 1,
+		},
+		{
+			name: "empty list",
+			seed: func(s *harvesttest.Server) {},
+			list: func(c *harvest.Client) (int, error) {
+				es, err := c.ListTimeEntries(ctx, harvest.TimeEntryFilter{})
+				return len(es), err
+			},
+			want:  0,
+			pages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := harvesttest.NewServer()
+			defer srv.Close()
+			srv.PerPage = 2
+			tt.seed(srv)
+
+			got, err := tt.list(srv.Client())
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d items, want %d", got, tt.want)
+			}
+			if n := len(srv.Requests()); n != tt.pages {
+				t.Errorf("fetched %d pages, want %d", n, tt.pages)
+			}
+		})
+	}
+}
+
+func TestIterStopsFetchingWhenDone(t *testing.T) {
+	srv := harvesttest.NewServer()
+	defer srv.Close()
+	srv.PerPage = 2
+	for i := range 6 {
+		srv.AddTimeEntry(harvest.TimeEntry{SpentDate: fmt.Sprintf("2026-10-%02d", i+1)})
+	}
+
+	seen := 0
+	for _, err := range srv.Client().IterTimeEntries(context.Background(), harvest.TimeEntryFilter{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen++; seen == 3 {
+			break
+		}
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("fetched %d pages for 3 items, want 2", n)
+	}
+}
+
+// TestPaginationEnvelope checks how the next page is chosen when links.next
+// and next_page disagree, using a hand-written server.
+func TestPaginationEnvelope(t *testing.T) {
+	tests := []struct {
+		name string
+		// page returns links.next and next_page for page n of 3.
+		page      func(base string, n int) (next *string, nextPage *int)
+		wantPages []string
+	}{
+		{
+			name: "links.next only",
+			page: func(base string, n int) (*string, *int) {
+				if n == 3 {
+					return nil, nil
+				}
+				next := fmt.Sprintf("%s/v2/projects?cursor=%d", base, n+1)
+				return &next, nil
+			},
+			wantPages: []string{"is_active=true", "cursor=2", "cursor=3"},
+		},
+		{
+			name: "next_page only",
+			page: func(base string, n int) (*string, *int) {
+				if n == 3 {
+					return nil, nil
+				}
+				next := n + 1
+				return nil, &next
+			},
+			wantPages: []string{"is_active=true", "is_active=true&page=2", "is_active=true&page=3"},
+		},
+		{
+			name: "links.next preferred",
+			page: func(base string, n int) (*string, *int) {
+				if n == 3 {
+					return nil, nil
+				}
+				link, next := fmt.Sprintf("%s/v2/projects?page=%d&cursor=x", base, n+1), n+1
+				return &link, &next
+			},
+			wantPages: []string{"is_active=true", "page=2&cursor=x", "page=3&cursor=x"},
+		},
+		{
+			name: "links.next off the API host falls back to next_page",
+			page: func(base string, n int) (*string, *int) {
+				if n == 3 {
+					return nil, nil
+				}
+				link, next := fmt.Sprintf("https://elsewhere.example/v2/projects?page=%d", n+1), n+1
+				return &link, &next
+			},
+			wantPages: []string{"is_active=true", "is_active=true&page=2", "is_active=true&page=3"},
+		},
+		{
+			name: "a page pointing at itself stops",
+			page: func(base string, n int) (*string, *int) {
+				link := base + "/v2/projects?is_active=true"
+				return &link, nil
+			},
+			wantPages: []string{"is_active=true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			var srv *httptest.Server
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				queries = append(queries, r.URL.RawQuery)
+				n := len(queries)
+				next, nextPage := tt.page(srv.URL, n)
+				json.NewEncoder(w).Encode(map[string]any{
+					"projects":    []harvest.Project{{ID: int64(n), Name: fmt.Sprint(n)}},
+					"per_page":    1,
+					"total_pages": 3,
+					"next_page":   nextPage,
+					"page":        n,
+					"links":       map[string]any{"next": next},
+				})
+			}))
+			defer srv.Close()
+
+			c, err := harvest.NewClient("1", "token", harvest.WithBaseURL(srv.URL+"/v2"), harvest.WithRetryPolicy(harvest.RetryPolicy{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			projects, err := c.ListProjects(context.Background())
+			if err != nil {
+				t.Fatalf("ListProjects: %v", err)
+			}
+			if len(projects) != len(tt.wantPages) {
+				t.Errorf("got %d projects, want %d", len(projects), len(tt.wantPages))
+			}
+			if strings.Join(queries, " | ") != strings.Join(tt.wantPages, " | ") {
+				t.Errorf("requested %q, want %q", queries, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     harvesttest.Fault
+		want      harvest.APIError
+		wantError string
+		is        func(error) bool
+	}{
+		{
+			name:  "oauth error",
+			fault: harvesttest.Fault{Status: 401, Body: `{"error":"invalid_token","error_description":"The access token is invalid."}`},
+			want: harvest.APIError{
+				StatusCode:  401,
+				Code:        "invalid_token",
+				Description: "The access token is invalid.",
+			},
+			wantError: "harvest API error 401 (GET /v2/users/me): The access token is invalid.",
+			is:        harvest.IsUnauthorized,
+		},
+		{
+			name:      "validation message",
+			fault:     harvesttest.Fault{Status: 422, Body: `{"message":"Spent date can't be blank"}`},
+			want:      harvest.APIError{StatusCode: 422, Message: "Spent date can't be blank"},
+			wantError: "harvest API error 422 (GET /v2/users/me): Spent date can't be blank",
+			is:        harvest.IsLocked,
+		},
+		{
+			name:      "body that isn't JSON",
+			fault:     harvesttest.Fault{Status: 502, Body: "<html>Bad Gateway</html>"},
+			want:      harvest.APIError{StatusCode: 502},
+			wantError: "harvest API error 502 (GET /v2/users/me): <html>Bad Gateway</html>",
+			is:        func(err error) bool { return !harvest.IsNotFound(err) },
+		},
+		{
+			name:      "empty body",
+			fault:     harvesttest.Fault{Status: 404},
+			want:      harvest.APIError{StatusCode: 404},
+			wantError: "harvest API error 404 (GET /v2/users/me): Not Found",
+			is:        harvest.IsNotFound,
+		},
+		{
+			name:      "rate limited",
+			fault:     harvesttest.Fault{Status: 429, Body: `{"message":"Too many requests"}`},
+			want:      harvest.APIError{StatusCode: 429, Message: "Too many requests"},
+			wantError: "harvest API error 429 (GET /v2/users/me): Too many requests",
+			is:        harvest.IsRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := harvesttest.NewServer()
+			defer srv.Close()
+			srv.InjectFault(tt.fault)
+
+			_, err := srv.Client().Me(context.Background())
+			var apiErr *harvest.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.want.StatusCode || apiErr.Code != tt.want.Code ||
+				apiErr.Description != tt.want.Description || apiErr.Message != tt.want.Message {
+				t.Errorf("got %+v, want %+v", *apiErr, tt.want)
+			}
+			if apiErr.Method != "GET" || apiErr.Path != "/v2/users/me" {
+				t.Errorf("request = %s %s, want GET /v2/users/me", apiErr.Method, apiErr.Path)
+			}
+			if apiErr.RequestID == "" {
+				t.Error("RequestID not taken from X-Request-Id")
+			}
+			if msg := err.Error(); !strings.HasPrefix(msg, tt.wantError+" [request ") {
+				t.Errorf("Error() = %q, want prefix %q", msg, tt.wantError)
+			}
+			if !tt.is(err) {
+				t.Errorf("status helper returned false for %v", err)
+			}
+		})
+	}
+}
+
+func TestEntryErrors(t *testing.T) {
+	ctx := context.Background()
+	locked := "Timesheet approved"
+	tests := []struct {
+		name  string
+		entry harvest.TimeEntry
+		call  func(*harvest.Client, int64) error
+		is    func(error) bool
+	}{
+		{
+			name:  "update locked",
+			entry: harvest.TimeEntry{IsLocked: true, LockedReason: &locked},
+			call: func(c *harvest.Client, id int64) error {
+				hours := 2.0
+				_, err := c.UpdateTimeEntry(ctx, id, harvest.TimeEntryUpdateRequest{Hours: &hours})
+				return err
+			},
+			is: harvest.IsLocked,
+		},
+		{
+			name:  "delete invoiced",
+			entry: harvest.TimeEntry{IsBilled: true},
+			call:  func(c *harvest.Client, id int64) error { return c.DeleteTimeEntry(ctx, id) },
+			is:    harvest.IsLocked,
+		},
+		{
+			name:  "restart approved",
+			entry: harvest.TimeEntry{IsClosed: true},
+			call: func(c *harvest.Client, id int64) error {
+				_, err := c.RestartTimeEntry(ctx, id)
+				return err
+			},
+			is: harvest.IsLocked,
+		},
+		{
+			name: "get unknown",
+			call: func(c *harvest.Client, id int64) error {
+				_, err := c.GetTimeEntry(ctx, id+1)
+				return err
+			},
+			is: harvest.IsNotFound,
+		},
+		{
+			name: "stop unknown",
+			call: func(c *harvest.Client, id int64) error {
+				_, err := c.StopTimeEntry(ctx, id+1)
+				return err
+			},
+			is: harvest.IsNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := harvesttest.NewServer()
+			defer srv.Close()
+			p := srv.AddProject(harvest.Project{Name: "Website", Active: true}, harvest.Task{ID: 1, Name: "Dev"})
+			tt.entry.Project, tt.entry.Task, tt.entry.SpentDate = p, harvest.Task{ID: 1, Name: "Dev"}, "2026-10-16"
+			e := srv.AddTimeEntry(tt.entry)
+
+			err := tt.call(srv.Client(), e.ID)
+			if err == nil || !tt.is(err) {
+				t.Fatalf("got %v", err)
+			}
+			if harvest.IsUnauthorized(err) || harvest.IsRateLimited(err) {
+				t.Errorf("%v matched an unrelated status helper", err)
+			}
+		})
+	}
+}
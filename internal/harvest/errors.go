@@ -0,0 +1,113 @@
+package harvest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned for any Harvest response with a non-success status.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	// RequestID is Harvest's X-Request-Id, useful when contacting support.
+	RequestID string
+	// Code and Description come from Harvest's `error`/`error_description` fields.
+	Code        string
+	Description string
+	// Message comes from Harvest's `message` field, used for validation errors.
+	Message string
+	// Body is the raw response body, kept for errors that don't parse as JSON.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	detail := e.Description
+	if detail == "" {
+		detail = e.Message
+	}
+	if detail == "" {
+		detail = e.Code
+	}
+	if detail == "" {
+		detail = strings.TrimSpace(e.Body)
+	}
+	if detail == "" {
+		detail = http.StatusText(e.StatusCode)
+	}
+
+	msg := fmt.Sprintf("harvest API error %d (%s %s): %s", e.StatusCode, e.Method, e.Path, detail)
+	if e.RequestID != "" {
+		msg += " [request " + e.RequestID + "]"
+	}
+	return msg
+}
+
+// newAPIError builds an APIError from a failed response, consuming its body.
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Body:       string(body),
+	}
+	if req := resp.Request; req != nil {
+		apiErr.Method = req.Method
+		apiErr.Path = req.URL.Path
+	}
+
+	var payload struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+		Message          string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		apiErr.Code = payload.Error
+		apiErr.Description = payload.ErrorDescription
+		apiErr.Message = payload.Message
+	}
+	return apiErr
+}
+
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnauthorized reports whether err is a 401, i.e. a missing, invalid or revoked token.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is a 403, i.e. the user lacks permission for the resource.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether err is a 404, e.g. a deleted time entry.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsLocked reports whether err is a 422, which Harvest returns when modifying
+// a locked, approved or invoiced time entry.
+func IsLocked(err error) bool {
+	return hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+// IsRateLimited reports whether err is a 429 that survived all retries.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
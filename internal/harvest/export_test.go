@@ -0,0 +1,20 @@
+package harvest
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Hooks for the harvest_test package, which can't reach unexported names
+// because it imports harvesttest.
+
+// WithSleep replaces the wait between retries.
+func WithSleep(sleep func(context.Context, time.Duration) error) Option {
+	return func(c *Client) { c.sleep = sleep }
+}
+
+// RetryAfter exposes retryAfter.
+func RetryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	return retryAfter(resp, fallback)
+}
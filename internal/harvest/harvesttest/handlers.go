@@ -0,0 +1,583 @@
+package harvesttest
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+func (s *Server) me(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	u := s.user
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) getCompany(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	c := s.company
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) listMyProjectAssignments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	out := make([]harvest.ProjectAssignment, 0, len(s.projects))
+	for _, p := range s.projects {
+		out = append(out, harvest.ProjectAssignment{
+			ID:              p.ID,
+			IsActive:        p.Active,
+			Project:         p,
+			Client:          p.Client,
+			TaskAssignments: append([]harvest.TaskAssignment(nil), s.taskAssignments[p.ID]...),
+		})
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "project_assignments", out)
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("is_active") == "true"
+	s.mu.Lock()
+	if !s.canManage() {
+		s.mu.Unlock()
+		forbidden(w)
+		return
+	}
+	var out []harvest.Project
+	for _, p := range s.projects {
+		if activeOnly && !p.Active {
+			continue
+		}
+		out = append(out, p)
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "projects", out)
+}
+
+func (s *Server) listTaskAssignments(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	if !s.canManage() {
+		s.mu.Unlock()
+		forbidden(w)
+		return
+	}
+	if s.findProject(id) == nil {
+		s.mu.Unlock()
+		notFound(w)
+		return
+	}
+	out := append([]harvest.TaskAssignment(nil), s.taskAssignments[id]...)
+	s.mu.Unlock()
+	writePage(s, w, r, "task_assignments", out)
+}
+
+func (s *Server) listTimeEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	match := func(e *harvest.TimeEntry) bool {
+		if v := q.Get("from"); v != "" && e.SpentDate < v {
+			return false
+		}
+		if v := q.Get("to"); v != "" && e.SpentDate > v {
+			return false
+		}
+		if v := q.Get("user_id"); v != "" && v != strconv.FormatInt(e.User.ID, 10) {
+			return false
+		}
+		if v := q.Get("project_id"); v != "" && v != strconv.FormatInt(e.Project.ID, 10) {
+			return false
+		}
+		if v := q.Get("client_id"); v != "" && v != strconv.FormatInt(e.Client.ID, 10) {
+			return false
+		}
+		if v := q.Get("task_id"); v != "" && v != strconv.FormatInt(e.Task.ID, 10) {
+			return false
+		}
+		if v := q.Get("is_running"); v != "" && v != strconv.FormatBool(e.IsRunning) {
+			return false
+		}
+		if v := q.Get("is_billed"); v != "" && v != strconv.FormatBool(e.IsBilled) {
+			return false
+		}
+		return true
+	}
+
+	s.mu.Lock()
+	var out []harvest.TimeEntry
+	for _, e := range s.timeEntries {
+		if match(e) {
+			out = append(out, *e)
+		}
+	}
+	s.mu.Unlock()
+
+	// Harvest lists the most recent entries first.
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].SpentDate != out[j].SpentDate {
+			return out[i].SpentDate > out[j].SpentDate
+		}
+		return out[i].ID > out[j].ID
+	})
+	writePage(s, w, r, "time_entries", out)
+}
+
+// timeEntryPayload covers the fields accepted when creating or updating a time entry.
+type timeEntryPayload struct {
+	UserID      *int64   `json:"user_id"`
+	ProjectID   *int64   `json:"project_id"`
+	TaskID      *int64   `json:"task_id"`
+	SpentDate   *string  `json:"spent_date"`
+	Notes       *string  `json:"notes"`
+	Hours       *float64 `json:"hours"`
+	StartedTime *string  `json:"started_time"`
+	EndedTime   *string  `json:"ended_time"`
+}
+
+func (s *Server) createTimeEntry(w http.ResponseWriter, r *http.Request) {
+	var p timeEntryPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		unprocessable(w, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.ProjectID == nil || s.findProject(*p.ProjectID) == nil {
+		unprocessable(w, "Project can't be blank")
+		return
+	}
+	if p.TaskID == nil || s.findTask(*p.ProjectID, *p.TaskID) == nil {
+		unprocessable(w, "Task can't be blank")
+		return
+	}
+	if p.SpentDate == nil {
+		unprocessable(w, "Spent date can't be blank")
+		return
+	}
+	spentDate, ok := parseDate(*p.SpentDate)
+	if !ok {
+		unprocessable(w, "Spent date is invalid")
+		return
+	}
+
+	project := s.findProject(*p.ProjectID)
+	ta := s.findTask(*p.ProjectID, *p.TaskID)
+	now := s.timestamp()
+	e := &harvest.TimeEntry{
+		ID:             s.newID(),
+		SpentDate:      spentDate,
+		User:           s.userRef(),
+		Client:         project.Client,
+		Project:        *project,
+		Task:           ta.Task,
+		TaskAssignment: *ta,
+		Billable:       ta.Billable,
+		Notes:          p.Notes,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if !s.applyTiming(w, e, p) {
+		return
+	}
+	if e.IsRunning {
+		s.stopRunning(e.User.ID)
+	}
+	s.timeEntries = append(s.timeEntries, e)
+	writeJSON(w, http.StatusCreated, e)
+}
+
+// applyTiming sets hours or start/end times from p. Without either a timer is
+// started, as Harvest does for duration-tracked accounts.
+func (s *Server) applyTiming(w http.ResponseWriter, e *harvest.TimeEntry, p timeEntryPayload) bool {
+	switch {
+	case p.StartedTime != nil && p.EndedTime != nil:
+		start, ok1 := parseClock(*p.StartedTime)
+		end, ok2 := parseClock(*p.EndedTime)
+		if !ok1 || !ok2 || end < start {
+			unprocessable(w, "Ended time must be after started time")
+			return false
+		}
+		e.StartedTime, e.EndedTime = p.StartedTime, p.EndedTime
+		e.Hours = round2(float64(end-start) / 60)
+	case p.Hours != nil:
+		if *p.Hours < 0 {
+			unprocessable(w, "Hours must be greater than or equal to 0")
+			return false
+		}
+		e.Hours = *p.Hours
+	default:
+		now := s.timestamp()
+		e.IsRunning = true
+		e.TimerStartedAt = &now
+	}
+	e.HoursWithoutTimer = e.Hours
+	e.RoundedHours = e.Hours
+	return true
+}
+
+func (s *Server) getTimeEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.findTimeEntry(id)
+	if e == nil {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) updateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var p timeEntryPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && err != io.EOF {
+		unprocessable(w, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.findTimeEntry(id)
+	if e == nil {
+		notFound(w)
+		return
+	}
+	if !checkEditable(w, e) {
+		return
+	}
+
+	projectID := e.Project.ID
+	if p.ProjectID != nil {
+		if s.findProject(*p.ProjectID) == nil {
+			unprocessable(w, "Project can't be blank")
+			return
+		}
+		projectID = *p.ProjectID
+	}
+	taskID := e.Task.ID
+	if p.TaskID != nil {
+		taskID = *p.TaskID
+	}
+	ta := s.findTask(projectID, taskID)
+	if ta == nil {
+		unprocessable(w, "Task can't be blank")
+		return
+	}
+	project := s.findProject(projectID)
+	e.Project, e.Client, e.Task = *project, project.Client, ta.Task
+
+	if p.SpentDate != nil {
+		d, ok := parseDate(*p.SpentDate)
+		if !ok {
+			unprocessable(w, "Spent date is invalid")
+			return
+		}
+		e.SpentDate = d
+	}
+	if p.Notes != nil {
+		e.Notes = p.Notes
+	}
+	if p.StartedTime != nil || p.EndedTime != nil {
+		if p.StartedTime == nil {
+			p.StartedTime = e.StartedTime
+		}
+		if p.EndedTime == nil {
+			p.EndedTime = e.EndedTime
+		}
+		if p.StartedTime == nil || p.EndedTime == nil {
+			unprocessable(w, "Started time and ended time are both required")
+			return
+		}
+		running := e.IsRunning
+		if !s.applyTiming(w, e, timeEntryPayload{StartedTime: p.StartedTime, EndedTime: p.EndedTime}) {
+			return
+		}
+		e.IsRunning = running
+	} else if p.Hours != nil {
+		if *p.Hours < 0 {
+			unprocessable(w, "Hours must be greater than or equal to 0")
+			return
+		}
+		e.Hours, e.HoursWithoutTimer, e.RoundedHours = *p.Hours, *p.Hours, *p.Hours
+		if e.IsRunning {
+			// Harvest restarts the timer's clock from the new total.
+			now := s.timestamp()
+			e.TimerStartedAt = &now
+		}
+	}
+	e.UpdatedAt = s.timestamp()
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) deleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.timeEntries {
+		if e.ID != id {
+			continue
+		}
+		if !checkEditable(w, e) {
+			return
+		}
+		s.timeEntries = append(s.timeEntries[:i], s.timeEntries[i+1:]...)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	notFound(w)
+}
+
+func (s *Server) restartTimeEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.findTimeEntry(id)
+	if e == nil {
+		notFound(w)
+		return
+	}
+	if !checkEditable(w, e) {
+		return
+	}
+	if e.IsRunning {
+		unprocessable(w, "Time entry is already running")
+		return
+	}
+	s.stopRunning(e.User.ID)
+	now := s.timestamp()
+	e.IsRunning = true
+	e.TimerStartedAt = &now
+	e.UpdatedAt = now
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) stopTimeEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.findTimeEntry(id)
+	if e == nil {
+		notFound(w)
+		return
+	}
+	if !e.IsRunning {
+		unprocessable(w, "Time entry is not running")
+		return
+	}
+	s.stop(e)
+	writeJSON(w, http.StatusOK, e)
+}
+
+// stopRunning stops any running timer of the user, as Harvest does when
+// another timer is started.
+func (s *Server) stopRunning(userID int64) {
+	for _, e := range s.timeEntries {
+		if e.IsRunning && e.User.ID == userID {
+			s.stop(e)
+		}
+	}
+}
+
+func (s *Server) stop(e *harvest.TimeEntry) {
+	if e.TimerStartedAt != nil {
+		if started, err := time.Parse(time.RFC3339, *e.TimerStartedAt); err == nil {
+			e.Hours = round2(e.Hours + s.Now().Sub(started).Hours())
+		}
+	}
+	e.HoursWithoutTimer = e.Hours
+	e.RoundedHours = e.Hours
+	e.IsRunning = false
+	e.TimerStartedAt = nil
+	e.UpdatedAt = s.timestamp()
+}
+
+// checkEditable rejects changes to locked, approved or invoiced entries with
+// the 422 Harvest returns.
+func checkEditable(w http.ResponseWriter, e *harvest.TimeEntry) bool {
+	switch {
+	case e.IsLocked:
+		reason := "Time entry is locked"
+		if e.LockedReason != nil {
+			reason = *e.LockedReason
+		}
+		unprocessable(w, reason)
+	case e.IsClosed:
+		unprocessable(w, "Time entry has been approved")
+	case e.IsBilled:
+		unprocessable(w, "Time entry has been invoiced")
+	default:
+		return true
+	}
+	return false
+}
+
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var out []harvest.InvoiceDetail
+	for _, inv := range s.invoices {
+		date := datePrefix(inv.IssuedAt)
+		if v := q.Get("from"); v != "" && date < v {
+			continue
+		}
+		if v := q.Get("to"); v != "" && date > v {
+			continue
+		}
+		out = append(out, inv)
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "invoices", out)
+}
+
+func (s *Server) listExpenses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	var out []harvest.ExpenseDetail
+	for _, e := range s.expenses {
+		if v := q.Get("from"); v != "" && e.SpentDate < v {
+			continue
+		}
+		if v := q.Get("to"); v != "" && e.SpentDate > v {
+			continue
+		}
+		out = append(out, e)
+	}
+	s.mu.Unlock()
+	writePage(s, w, r, "expenses", out)
+}
+
+func (s *Server) createExpense(w http.ResponseWriter, r *http.Request) {
+	var req harvest.ExpenseCreateRequest
+	var receipt *Receipt
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			unprocessable(w, "Invalid multipart body: "+err.Error())
+			return
+		}
+		req.ProjectID, _ = strconv.ParseInt(r.FormValue("project_id"), 10, 64)
+		req.ExpenseCategoryID, _ = strconv.ParseInt(r.FormValue("expense_category_id"), 10, 64)
+		req.SpentDate = r.FormValue("spent_date")
+		req.TotalCost, _ = strconv.ParseFloat(r.FormValue("total_cost"), 64)
+		if v := r.FormValue("notes"); v != "" {
+			req.Notes = &v
+		}
+		if v := r.FormValue("billable"); v != "" {
+			b := v == "true"
+			req.Billable = &b
+		}
+		if f, hdr, err := r.FormFile("receipt"); err == nil {
+			data, _ := io.ReadAll(f)
+			f.Close()
+			receipt = &Receipt{Filename: hdr.Filename, ContentType: hdr.Header.Get("Content-Type"), Data: data}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		unprocessable(w, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project := s.findProject(req.ProjectID)
+	if project == nil {
+		unprocessable(w, "Project can't be blank")
+		return
+	}
+	category := s.findExpenseCategory(req.ExpenseCategoryID)
+	if category == nil {
+		unprocessable(w, "Expense category can't be blank")
+		return
+	}
+	spentDate, ok := parseDate(req.SpentDate)
+	if !ok {
+		unprocessable(w, "Spent date is invalid")
+		return
+	}
+
+	now := s.timestamp()
+	e := harvest.ExpenseDetail{
+		ID:              s.newID(),
+		SpentDate:       spentDate,
+		Project:         *project,
+		ExpenseCategory: *category,
+		TotalCost:       req.TotalCost,
+		Notes:           req.Notes,
+		Billable:        req.Billable == nil || *req.Billable,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.expenses = append(s.expenses, e)
+	if receipt != nil {
+		s.receipts[e.ID] = *receipt
+	}
+	writeJSON(w, http.StatusCreated, e)
+}
+
+func (s *Server) listExpenseCategories(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	out := append([]harvest.ExpenseCategory(nil), s.expenseCategories...)
+	s.mu.Unlock()
+	writePage(s, w, r, "expense_categories", out)
+}
+
+// parseDate accepts a YYYY-MM-DD date or an RFC 3339 timestamp and returns the date.
+func parseDate(v string) (string, bool) {
+	if _, err := time.Parse("2006-01-02", v); err == nil {
+		return v, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.Format("2006-01-02"), true
+	}
+	return "", false
+}
+
+// parseClock parses "15:04" or "3:04pm" into minutes after midnight.
+func parseClock(v string) (int, bool) {
+	for _, layout := range []string{"15:04", "3:04pm", "3:04PM"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Hour()*60 + t.Minute(), true
+		}
+	}
+	return 0, false
+}
+
+func datePrefix(v string) string {
+	if len(v) > 10 {
+		return v[:10]
+	}
+	return v
+}
+
+func round2(h float64) float64 {
+	return math.Round(h*100) / 100
+}
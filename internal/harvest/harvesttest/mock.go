@@ -0,0 +1,190 @@
+package harvesttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+// Mock is a hand-written harvest.API for tests that don't need HTTP. Set the
+// Func field for each operation under test; calling an operation whose Func
+// is nil returns an error. Every call is recorded by name.
+type Mock struct {
+	MeFunc                       func(ctx context.Context) (*harvest.UserProfile, error)
+	CompanyFunc                  func(ctx context.Context) (*harvest.Company, error)
+	ListProjectsFunc             func(ctx context.Context) ([]harvest.Project, error)
+	ListTasksFunc                func(ctx context.Context, projectID int64) ([]harvest.Task, error)
+	ListMyProjectAssignmentsFunc func(ctx context.Context) ([]harvest.ProjectAssignment, error)
+	CreateTimeEntryFunc          func(ctx context.Context, entry harvest.TimeEntryRequest) (*harvest.TimeEntryResponse, error)
+	ListTimeEntriesFunc          func(ctx context.Context, filter harvest.TimeEntryFilter) ([]harvest.TimeEntry, error)
+	GetTimeEntryFunc             func(ctx context.Context, timeEntryID int64) (*harvest.TimeEntry, error)
+	RestartTimeEntryFunc         func(ctx context.Context, timeEntryID int64) (*harvest.TimeEntry, error)
+	StopTimeEntryFunc            func(ctx context.Context, timeEntryID int64) (*harvest.TimeEntry, error)
+	UpdateTimeEntryFunc          func(ctx context.Context, timeEntryID int64, update harvest.TimeEntryUpdateRequest) (*harvest.TimeEntry, error)
+	DeleteTimeEntryFunc          func(ctx context.Context, timeEntryID int64) error
+	ListInvoicesFunc             func(ctx context.Context, from, to *string) ([]harvest.InvoiceDetail, error)
+	ListExpensesFunc             func(ctx context.Context, from, to *string) ([]harvest.ExpenseDetail, error)
+	ListExpenseCategoriesFunc    func(ctx context.Context) ([]harvest.ExpenseCategory, error)
+	CreateExpenseFunc            func(ctx context.Context, reqBody harvest.ExpenseCreateRequest) (*harvest.ExpenseDetail, error)
+	CreateExpenseWithReceiptFunc func(ctx context.Context, reqBody harvest.ExpenseCreateRequest, receiptPath string) (*harvest.ExpenseDetail, error)
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var _ harvest.API = (*Mock)(nil)
+
+// Calls returns the names of the operations called so far, in order.
+func (m *Mock) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+func (m *Mock) record(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, name)
+}
+
+func notSet(name string) error {
+	return fmt.Errorf("harvesttest: Mock.%sFunc not set", name)
+}
+
+func (m *Mock) Me(ctx context.Context) (*harvest.UserProfile, error) {
+	m.record("Me")
+	if m.MeFunc == nil {
+		return nil, notSet("Me")
+	}
+	return m.MeFunc(ctx)
+}
+
+func (m *Mock) Company(ctx context.Context) (*harvest.Company, error) {
+	m.record("Company")
+	if m.CompanyFunc == nil {
+		return nil, notSet("Company")
+	}
+	return m.CompanyFunc(ctx)
+}
+
+func (m *Mock) ListProjects(ctx context.Context) ([]harvest.Project, error) {
+	m.record("ListProjects")
+	if m.ListProjectsFunc == nil {
+		return nil, notSet("ListProjects")
+	}
+	return m.ListProjectsFunc(ctx)
+}
+
+func (m *Mock) ListTasks(ctx context.Context, projectID int64) ([]harvest.Task, error) {
+	m.record("ListTasks")
+	if m.ListTasksFunc == nil {
+		return nil, notSet("ListTasks")
+	}
+	return m.ListTasksFunc(ctx, projectID)
+}
+
+func (m *Mock) ListMyProjectAssignments(ctx context.Context) ([]harvest.ProjectAssignment, error) {
+	m.record("ListMyProjectAssignments")
+	if m.ListMyProjectAssignmentsFunc == nil {
+		return nil, notSet("ListMyProjectAssignments")
+	}
+	return m.ListMyProjectAssignmentsFunc(ctx)
+}
+
+func (m *Mock) CreateTimeEntry(ctx context.Context, entry harvest.TimeEntryRequest) (*harvest.TimeEntryResponse, error) {
+	m.record("CreateTimeEntry")
+	if m.CreateTimeEntryFunc == nil {
+		return nil, notSet("CreateTimeEntry")
+	}
+	return m.CreateTimeEntryFunc(ctx, entry)
+}
+
+func (m *Mock) ListTimeEntries(ctx context.Context, filter harvest.TimeEntryFilter) ([]harvest.TimeEntry, error) {
+	m.record("ListTimeEntries")
+	if m.ListTimeEntriesFunc == nil {
+		return nil, notSet("ListTimeEntries")
+	}
+	return m.ListTimeEntriesFunc(ctx, filter)
+}
+
+func (m *Mock) GetTimeEntry(ctx context.Context, timeEntryID int64) (*harvest.TimeEntry, error) {
+	m.record("GetTimeEntry")
+	if m.GetTimeEntryFunc == nil {
+		return nil, notSet("GetTimeEntry")
+	}
+	return m.GetTimeEntryFunc(ctx, timeEntryID)
+}
+
+func (m *Mock) RestartTimeEntry(ctx context.Context, timeEntryID int64) (*harvest.TimeEntry, error) {
+	m.record("RestartTimeEntry")
+	if m.RestartTimeEntryFunc == nil {
+		return nil, notSet("RestartTimeEntry")
+	}
+	return m.RestartTimeEntryFunc(ctx, timeEntryID)
+}
+
+func (m *Mock) StopTimeEntry(ctx context.Context, timeEntryID int64) (*harvest.TimeEntry, error) {
+	m.record("StopTimeEntry")
+	if m.StopTimeEntryFunc == nil {
+		return nil, notSet("StopTimeEntry")
+	}
+	return m.StopTimeEntryFunc(ctx, timeEntryID)
+}
+
+func (m *Mock) UpdateTimeEntry(ctx context.Context, timeEntryID int64, update harvest.TimeEntryUpdateRequest) (*harvest.TimeEntry, error) {
+	m.record("UpdateTimeEntry")
+	if m.UpdateTimeEntryFunc == nil {
+		return nil, notSet("UpdateTimeEntry")
+	}
+	return m.UpdateTimeEntryFunc(ctx, timeEntryID, update)
+}
+
+func (m *Mock) DeleteTimeEntry(ctx context.Context, timeEntryID int64) error {
+	m.record("DeleteTimeEntry")
+	if m.DeleteTimeEntryFunc == nil {
+		return notSet("DeleteTimeEntry")
+	}
+	return m.DeleteTimeEntryFunc(ctx, timeEntryID)
+}
+
+func (m *Mock) ListInvoices(ctx context.Context, from, to *string) ([]harvest.InvoiceDetail, error) {
+	m.record("ListInvoices")
+	if m.ListInvoicesFunc == nil {
+		return nil, notSet("ListInvoices")
+	}
+	return m.ListInvoicesFunc(ctx, from, to)
+}
+
+func (m *Mock) ListExpenses(ctx context.Context, from, to *string) ([]harvest.ExpenseDetail, error) {
+	m.record("ListExpenses")
+	if m.ListExpensesFunc == nil {
+		return nil, notSet("ListExpenses")
+	}
+	return m.ListExpensesFunc(ctx, from, to)
+}
+
+func (m *Mock) ListExpenseCategories(ctx context.Context) ([]harvest.ExpenseCategory, error) {
+	m.record("ListExpenseCategories")
+	if m.ListExpenseCategoriesFunc == nil {
+		return nil, notSet("ListExpenseCategories")
+	}
+	return m.ListExpenseCategoriesFunc(ctx)
+}
+
+func (m *Mock) CreateExpense(ctx context.Context, reqBody harvest.ExpenseCreateRequest) (*harvest.ExpenseDetail, error) {
+	m.record("CreateExpense")
+	if m.CreateExpenseFunc == nil {
+		return nil, notSet("CreateExpense")
+	}
+	return m.CreateExpenseFunc(ctx, reqBody)
+}
+
+func (m *Mock) CreateExpenseWithReceipt(ctx context.Context, reqBody harvest.ExpenseCreateRequest, receiptPath string) (*harvest.ExpenseDetail, error) {
+	m.record("CreateExpenseWithReceipt")
+	if m.CreateExpenseWithReceiptFunc == nil {
+		return nil, notSet("CreateExpenseWithReceipt")
+	}
+	return m.CreateExpenseWithReceiptFunc(ctx, reqBody, receiptPath)
+}
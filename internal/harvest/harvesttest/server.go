@@ -0,0 +1,297 @@
+// Package harvesttest provides an in-process fake of the Harvest v2 API for
+// exercising harvest.Client and the CLI offline.
+package harvesttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+// Credentials accepted by a new Server.
+const (
+	AccountID   = "123456"
+	AccessToken = "test-token"
+)
+
+// Fault is a canned error response served instead of the next request that
+// matches Method and Path. Empty Method or Path match anything.
+type Fault struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+	Header http.Header
+}
+
+// Request records a request received by the Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// Receipt is a file uploaded with a multipart expense.
+type Receipt struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Server is a fake Harvest API backed by an in-memory store. It serves the
+// same paths as the real API under /v2, paginates list responses and returns
+// Harvest-shaped errors for bad credentials, unknown IDs and locked entries.
+type Server struct {
+	*httptest.Server
+
+	// PerPage is the default page size for list endpoints, kept small so
+	// pagination is exercised. Requests may override it with per_page.
+	PerPage int
+	// Now is used for timestamps and running timers.
+	Now func() time.Time
+
+	mu                sync.Mutex
+	nextID            int64
+	user              harvest.UserProfile
+	company           harvest.Company
+	projects          []harvest.Project
+	taskAssignments   map[int64][]harvest.TaskAssignment
+	timeEntries       []*harvest.TimeEntry
+	invoices          []harvest.InvoiceDetail
+	expenses          []harvest.ExpenseDetail
+	expenseCategories []harvest.ExpenseCategory
+	receipts          map[int64]Receipt
+	faults            []Fault
+	requests          []Request
+}
+
+// NewServer starts a fake Harvest server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		PerPage: 100,
+		Now:     time.Now,
+		nextID:  1000,
+		user: harvest.UserProfile{
+			ID:             1,
+			FirstName:      "Test",
+			LastName:       "User",
+			Email:          "test@example.com",
+			Timezone:       "Eastern Time (US & Canada)",
+			WeeklyCapacity: 40 * 3600,
+			IsActive:       true,
+			Roles:          []string{},
+			AccessRoles:    []string{"administrator"},
+		},
+		company: harvest.Company{
+			BaseURI:      "https://example.harvestapp.com",
+			FullDomain:   "example.harvestapp.com",
+			Name:         "Example Co",
+			IsActive:     true,
+			WeekStartDay: "Monday",
+			TimeFormat:   "hours_minutes",
+			ClockFormat:  "24h",
+		},
+		taskAssignments: make(map[int64][]harvest.TaskAssignment),
+		receipts:        make(map[int64]Receipt),
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// BaseURL is the API root to pass to harvest.WithBaseURL.
+func (s *Server) BaseURL() string {
+	return s.URL + "/v2"
+}
+
+// Client returns a harvest.Client talking to the server. Retries are
+// disabled unless overridden in opts, so injected faults surface directly.
+func (s *Server) Client(opts ...harvest.Option) *harvest.Client {
+	opts = append([]harvest.Option{
+		harvest.WithBaseURL(s.BaseURL()),
+		harvest.WithHTTPClient(s.Server.Client()),
+		harvest.WithRetryPolicy(harvest.RetryPolicy{}),
+	}, opts...)
+	c, err := harvest.NewClient(AccountID, AccessToken, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// InjectFault queues an error response for the next matching request.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, f)
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/users/me", s.me)
+	mux.HandleFunc("GET /v2/company", s.getCompany)
+	mux.HandleFunc("GET /v2/users/me/project_assignments", s.listMyProjectAssignments)
+	mux.HandleFunc("GET /v2/projects", s.listProjects)
+	mux.HandleFunc("GET /v2/projects/{id}/task_assignments", s.listTaskAssignments)
+	mux.HandleFunc("GET /v2/time_entries", s.listTimeEntries)
+	mux.HandleFunc("POST /v2/time_entries", s.createTimeEntry)
+	mux.HandleFunc("GET /v2/time_entries/{id}", s.getTimeEntry)
+	mux.HandleFunc("PATCH /v2/time_entries/{id}", s.updateTimeEntry)
+	mux.HandleFunc("DELETE /v2/time_entries/{id}", s.deleteTimeEntry)
+	mux.HandleFunc("PATCH /v2/time_entries/{id}/restart", s.restartTimeEntry)
+	mux.HandleFunc("PATCH /v2/time_entries/{id}/stop", s.stopTimeEntry)
+	mux.HandleFunc("GET /v2/invoices", s.listInvoices)
+	mux.HandleFunc("GET /v2/expenses", s.listExpenses)
+	mux.HandleFunc("POST /v2/expenses", s.createExpense)
+	mux.HandleFunc("GET /v2/expense_categories", s.listExpenseCategories)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, `{"status":404,"error":"Not Found"}`)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := s.record(r)
+		if f, ok := s.takeFault(r); ok {
+			for k, vs := range f.Header {
+				for _, v := range vs {
+					w.Header().Add(k, v)
+				}
+			}
+			writeError(w, f.Status, f.Body)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+AccessToken || r.Header.Get("Harvest-Account-ID") != AccountID {
+			writeError(w, http.StatusUnauthorized, `{"error":"invalid_token","error_description":"The access token provided is expired, revoked, malformed or invalid for other reasons."}`)
+			return
+		}
+		r.Body = readCloser(body)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) record(r *http.Request) []byte {
+	body := readAll(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Body:   body,
+	})
+	return body
+}
+
+func (s *Server) takeFault(r *http.Request) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if (f.Method == "" || f.Method == r.Method) && (f.Path == "" || f.Path == r.URL.Path) {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			return f, true
+		}
+	}
+	return Fault{}, false
+}
+
+// writePage serves one page of items under key with Harvest's pagination envelope.
+func writePage[T any](s *Server, w http.ResponseWriter, r *http.Request, key string, items []T) {
+	q := r.URL.Query()
+	perPage := s.PerPage
+	if v, err := strconv.Atoi(q.Get("per_page")); err == nil && v > 0 {
+		perPage = v
+	}
+	page := 1
+	if v, err := strconv.Atoi(q.Get("page")); err == nil && v > 0 {
+		page = v
+	}
+
+	total := len(items)
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+
+	link := func(p int) string {
+		lq := r.URL.Query()
+		lq.Set("page", strconv.Itoa(p))
+		lq.Set("per_page", strconv.Itoa(perPage))
+		return s.URL + r.URL.Path + "?" + lq.Encode()
+	}
+	var nextPage, prevPage *int
+	var nextLink, prevLink *string
+	if page < totalPages {
+		n, l := page+1, link(page+1)
+		nextPage, nextLink = &n, &l
+	}
+	if page > 1 {
+		p, l := page-1, link(page-1)
+		prevPage, prevLink = &p, &l
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		key:             append([]T{}, items[start:end]...),
+		"per_page":      perPage,
+		"total_pages":   totalPages,
+		"total_entries": total,
+		"next_page":     nextPage,
+		"previous_page": prevPage,
+		"page":          page,
+		"links": map[string]any{
+			"first":    link(1),
+			"next":     nextLink,
+			"previous": prevLink,
+			"last":     link(totalPages),
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Request-Id", fmt.Sprintf("harvesttest-%d", time.Now().UnixNano()))
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, `{"status":404,"error":"Not Found"}`)
+}
+
+func forbidden(w http.ResponseWriter) {
+	writeError(w, http.StatusForbidden, `{"status":403,"error":"Forbidden","error_description":"You do not have permission to perform that action."}`)
+}
+
+func unprocessable(w http.ResponseWriter, message string) {
+	b, _ := json.Marshal(map[string]string{"message": message})
+	writeError(w, http.StatusUnprocessableEntity, string(b))
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		notFound(w)
+		return 0, false
+	}
+	return id, true
+}
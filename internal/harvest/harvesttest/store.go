@@ -0,0 +1,230 @@
+package harvesttest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+)
+
+// SetUser replaces the user the access token belongs to.
+func (s *Server) SetUser(u harvest.UserProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = u
+}
+
+// User returns the user the access token belongs to.
+func (s *Server) User() harvest.UserProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.user
+}
+
+// SetCompany replaces the account settings, for example to switch the
+// account to timestamp tracking.
+func (s *Server) SetCompany(c harvest.Company) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.company = c
+}
+
+// Company returns the account settings.
+func (s *Server) Company() harvest.Company {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.company
+}
+
+// AddProject stores a project and assigns the given tasks to it. Zero IDs are
+// filled in; the stored project is returned. The current user is assigned to
+// every project, so it also shows up in /users/me/project_assignments.
+func (s *Server) AddProject(p harvest.Project, tasks ...harvest.Task) harvest.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = s.newID()
+	}
+	for _, t := range tasks {
+		if t.ID == 0 {
+			t.ID = s.newID()
+		}
+		s.taskAssignments[p.ID] = append(s.taskAssignments[p.ID], harvest.TaskAssignment{
+			ID:       s.newID(),
+			Billable: true,
+			IsActive: true,
+			Task:     t,
+		})
+	}
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// AddTimeEntry stores a time entry as-is, filling in a zero ID, the current
+// user and timestamps. The stored entry is returned.
+func (s *Server) AddTimeEntry(e harvest.TimeEntry) harvest.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.ID == 0 {
+		e.ID = s.newID()
+	}
+	if e.User.ID == 0 {
+		e.User = s.userRef()
+	}
+	now := s.timestamp()
+	if e.CreatedAt == "" {
+		e.CreatedAt = now
+	}
+	if e.UpdatedAt == "" {
+		e.UpdatedAt = now
+	}
+	s.timeEntries = append(s.timeEntries, &e)
+	return e
+}
+
+// TimeEntry returns the stored time entry with the given ID.
+func (s *Server) TimeEntry(id int64) (harvest.TimeEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.findTimeEntry(id); e != nil {
+		return *e, true
+	}
+	return harvest.TimeEntry{}, false
+}
+
+// TimeEntries returns all stored time entries in insertion order.
+func (s *Server) TimeEntries() []harvest.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]harvest.TimeEntry, 0, len(s.timeEntries))
+	for _, e := range s.timeEntries {
+		out = append(out, *e)
+	}
+	return out
+}
+
+// AddInvoice stores an invoice, filling in a zero ID.
+func (s *Server) AddInvoice(inv harvest.InvoiceDetail) harvest.InvoiceDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inv.ID == 0 {
+		inv.ID = s.newID()
+	}
+	s.invoices = append(s.invoices, inv)
+	return inv
+}
+
+// AddExpenseCategory stores an expense category, filling in a zero ID.
+func (s *Server) AddExpenseCategory(c harvest.ExpenseCategory) harvest.ExpenseCategory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.ID == 0 {
+		c.ID = s.newID()
+	}
+	s.expenseCategories = append(s.expenseCategories, c)
+	return c
+}
+
+// AddExpense stores an expense, filling in a zero ID.
+func (s *Server) AddExpense(e harvest.ExpenseDetail) harvest.ExpenseDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.ID == 0 {
+		e.ID = s.newID()
+	}
+	s.expenses = append(s.expenses, e)
+	return e
+}
+
+// Expenses returns all stored expenses in insertion order.
+func (s *Server) Expenses() []harvest.ExpenseDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]harvest.ExpenseDetail(nil), s.expenses...)
+}
+
+// Receipt returns the receipt uploaded with the given expense.
+func (s *Server) Receipt(expenseID int64) (Receipt, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.receipts[expenseID]
+	return r, ok
+}
+
+// The helpers below expect s.mu to be held.
+
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) userRef() harvest.User {
+	return harvest.User{ID: s.user.ID, Name: s.user.Name()}
+}
+
+// canManage reports whether the current user may use admin/manager-only
+// endpoints such as /projects.
+func (s *Server) canManage() bool {
+	for _, role := range s.user.AccessRoles {
+		if role == "administrator" || role == "manager" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) timestamp() string {
+	return s.Now().UTC().Format(time.RFC3339)
+}
+
+func (s *Server) findProject(id int64) *harvest.Project {
+	for i := range s.projects {
+		if s.projects[i].ID == id {
+			return &s.projects[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findTask(projectID, taskID int64) *harvest.TaskAssignment {
+	tas := s.taskAssignments[projectID]
+	for i := range tas {
+		if tas[i].Task.ID == taskID {
+			return &tas[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) findTimeEntry(id int64) *harvest.TimeEntry {
+	for _, e := range s.timeEntries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *Server) findExpenseCategory(id int64) *harvest.ExpenseCategory {
+	for i := range s.expenseCategories {
+		if s.expenseCategories[i].ID == id {
+			return &s.expenseCategories[i]
+		}
+	}
+	return nil
+}
+
+func readAll(r *http.Request) []byte {
+	if r.Body == nil {
+		return nil
+	}
+	b, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	return b
+}
+
+func readCloser(b []byte) io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(b))
+}
@@ -1,5 +1,7 @@
 package harvest
 
+import "strings"
+
 // Project represents a Harvest project.
 type Project struct {
 	ID     int64         `json:"id"`
@@ -14,25 +16,82 @@ type Task struct {
 	Name string `json:"name"`
 }
 
-// Task represents a Harvest task.
+// TaskAssignment represents a task assigned to a project.
 type TaskAssignment struct {
-	Task Task `json:"task"`
+	ID         int64    `json:"id"`
+	Billable   bool     `json:"billable"`
+	IsActive   bool     `json:"is_active"`
+	HourlyRate *float64 `json:"hourly_rate"`
+	Task       Task     `json:"task"`
+}
+
+// ProjectAssignment is a project the current user is assigned to, together
+// with the tasks they can track time against.
+type ProjectAssignment struct {
+	ID               int64            `json:"id"`
+	IsActive         bool             `json:"is_active"`
+	IsProjectManager bool             `json:"is_project_manager"`
+	Project          Project          `json:"project"`
+	Client           HarvestClient    `json:"client"`
+	TaskAssignments  []TaskAssignment `json:"task_assignments"`
+}
+
+// Tasks returns the assignment's active tasks.
+func (pa ProjectAssignment) Tasks() []Task {
+	active := make([]TaskAssignment, 0, len(pa.TaskAssignments))
+	for _, ta := range pa.TaskAssignments {
+		if ta.IsActive {
+			active = append(active, ta)
+		}
+	}
+	return pluckTasks(active)
 }
 
 // func (ta TaskAssignment) Name() string { return ta.Task.Name }
 // func (ta TaskAssignment) ID() int64    { return ta.Task.ID }
 
-// TimeEntryRequest is the payload for creating a time entry.
+// TimeEntryRequest is the payload for creating a time entry. Leaving Hours
+// and the times nil starts a running timer. Accounts that track by duration
+// take Hours, even zero, to create a stopped entry; accounts that track by
+// start and end time take StartedTime and EndedTime instead.
 type TimeEntryRequest struct {
-	ProjectID int64  `json:"project_id"`
-	TaskID    int64  `json:"task_id"`
-	SpendDate string `json:"spent_date"`
-	Notes     string `json:"notes"`
+	ProjectID   int64    `json:"project_id"`
+	TaskID      int64    `json:"task_id"`
+	SpendDate   string   `json:"spent_date"`
+	Notes       string   `json:"notes"`
+	Hours       *float64 `json:"hours,omitempty"`
+	StartedTime *string  `json:"started_time,omitempty"`
+	EndedTime   *string  `json:"ended_time,omitempty"`
 }
 
-// TimeEntryUpdateRequest is the payload for updating a time entry.
+// TimeEntryUpdateRequest is the payload for updating a time entry. Only the
+// fields that are set are sent, so Harvest leaves the rest unchanged.
 type TimeEntryUpdateRequest struct {
-	Hours float64 `json:"hours"`
+	ProjectID   *int64   `json:"project_id,omitempty"`
+	TaskID      *int64   `json:"task_id,omitempty"`
+	SpentDate   *string  `json:"spent_date,omitempty"`
+	Notes       *string  `json:"notes,omitempty"`
+	Hours       *float64 `json:"hours,omitempty"`
+	StartedTime *string  `json:"started_time,omitempty"`
+	EndedTime   *string  `json:"ended_time,omitempty"`
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (r TimeEntryUpdateRequest) IsEmpty() bool {
+	return r == TimeEntryUpdateRequest{}
+}
+
+// TimeEntryFilter narrows ListTimeEntries. Nil fields are not filtered on;
+// From and To are YYYY-MM-DD dates.
+type TimeEntryFilter struct {
+	From      *string
+	To        *string
+	UserID    *int64
+	ClientID  *int64
+	ProjectID *int64
+	TaskID    *int64
+	IsRunning *bool
+	IsBilled  *bool
 }
 
 // TimeEntryResponse represents the API response for a created time entry.
@@ -82,6 +141,55 @@ type User struct {
 	Name string `json:"name"`
 }
 
+// UserProfile is the full profile of the authenticated user, as returned by /users/me.
+type UserProfile struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Timezone  string `json:"timezone"`
+	// WeeklyCapacity is the number of seconds the user is expected to work per week.
+	WeeklyCapacity int      `json:"weekly_capacity"`
+	IsActive       bool     `json:"is_active"`
+	Roles          []string `json:"roles"`
+	AccessRoles    []string `json:"access_roles"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// Name returns the user's full name.
+func (u UserProfile) Name() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// WeeklyCapacityHours returns WeeklyCapacity in hours.
+func (u UserProfile) WeeklyCapacityHours() float64 {
+	return float64(u.WeeklyCapacity) / 3600
+}
+
+// Company holds the account settings returned by /company.
+type Company struct {
+	BaseURI    string `json:"base_uri"`
+	FullDomain string `json:"full_domain"`
+	Name       string `json:"name"`
+	IsActive   bool   `json:"is_active"`
+	// WeekStartDay is "Saturday", "Sunday" or "Monday".
+	WeekStartDay string `json:"week_start_day"`
+	// WantsTimestampTimers is true when the account tracks start and end
+	// times rather than durations.
+	WantsTimestampTimers bool `json:"wants_timestamp_timers"`
+	// TimeFormat is "decimal" or "hours_minutes".
+	TimeFormat string `json:"time_format"`
+	// ClockFormat is "12h" or "24h".
+	ClockFormat        string `json:"clock"`
+	ExpenseFeature     bool   `json:"expense_feature"`
+	InvoiceFeature     bool   `json:"invoice_feature"`
+	EstimateFeature    bool   `json:"estimate_feature"`
+	ApprovalFeature    bool   `json:"approval_feature"`
+	ThousandsSeparator string `json:"thousands_separator"`
+	DecimalSymbol      string `json:"decimal_symbol"`
+}
+
 // HarvestClient represents a Harvest client.
 type HarvestClient struct {
 	ID   int64  `json:"id"`
@@ -127,13 +235,6 @@ type InvoiceDetail struct {
 	UpdatedAt string        `json:"updated_at"`
 }
 
-type InvoiceListResponse struct {
-	Invoices   []InvoiceDetail `json:"invoices"`
-	PerPage    int             `json:"per_page"`
-	TotalPages int             `json:"total_pages"`
-	NextPage   *int            `json:"next_page"`
-}
-
 type ExpenseDetail struct {
 	ID              int64           `json:"id"`
 	SpentDate       string          `json:"spent_date"`
@@ -151,20 +252,6 @@ type ExpenseCategory struct {
 	Name string `json:"name"`
 }
 
-type ExpenseListResponse struct {
-	Expenses   []ExpenseDetail `json:"expenses"`
-	PerPage    int             `json:"per_page"`
-	TotalPages int             `json:"total_pages"`
-	NextPage   *int            `json:"next_page"`
-}
-
-type ExpenseCategoryListResponse struct {
-	ExpenseCategories []ExpenseCategory `json:"expense_categories"`
-	PerPage           int               `json:"per_page"`
-	TotalPages        int               `json:"total_pages"`
-	NextPage          *int              `json:"next_page"`
-}
-
 type ExpenseCreateRequest struct {
 	ProjectID         int64   `json:"project_id"`
 	ExpenseCategoryID int64   `json:"expense_category_id"`
@@ -0,0 +1,119 @@
+package harvest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageLinks holds the navigation links Harvest returns with every list response.
+type PageLinks struct {
+	First    string  `json:"first"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Last     string  `json:"last"`
+}
+
+// pageMeta is the pagination envelope shared by all Harvest list endpoints.
+type pageMeta struct {
+	PerPage      int       `json:"per_page"`
+	TotalPages   int       `json:"total_pages"`
+	TotalEntries int       `json:"total_entries"`
+	NextPage     *int      `json:"next_page"`
+	Page         int       `json:"page"`
+	Links        PageLinks `json:"links"`
+}
+
+// paginate returns an iterator over every item of the list endpoint at path.
+// Items are decoded from the given response key (e.g. "projects") and pages
+// are fetched lazily, so only one page is held in memory at a time.
+func paginate[T any](ctx context.Context, c *Client, path, key string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for path != "" {
+			req, err := c.newRequest(ctx, "GET", path, nil)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var body json.RawMessage
+			if err := c.do(req, &body); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var meta pageMeta
+			if err := json.Unmarshal(body, &meta); err != nil {
+				yield(zero, err)
+				return
+			}
+			var page map[string]json.RawMessage
+			if err := json.Unmarshal(body, &page); err != nil {
+				yield(zero, err)
+				return
+			}
+			var items []T
+			if raw, ok := page[key]; ok {
+				if err := json.Unmarshal(raw, &items); err != nil {
+					yield(zero, fmt.Errorf("decoding %s: %w", key, err))
+					return
+				}
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			next, err := nextPagePath(c.baseURL, path, meta)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if next == path {
+				// Guard against a server that keeps pointing at the same page.
+				return
+			}
+			path = next
+		}
+	}
+}
+
+// collect drains an iterator into a slice, stopping at the first error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// nextPagePath works out the request path for the page after the current one.
+// links.next is preferred when it points back at the API, otherwise next_page
+// is applied to the current path. An empty path means there are no more pages.
+func nextPagePath(base, current string, meta pageMeta) (string, error) {
+	if meta.Links.Next != nil && strings.HasPrefix(*meta.Links.Next, base+"/") {
+		return strings.TrimPrefix(*meta.Links.Next, base), nil
+	}
+	if meta.NextPage == nil {
+		return "", nil
+	}
+
+	u, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("page", strconv.Itoa(*meta.NextPage))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
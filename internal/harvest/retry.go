@@ -0,0 +1,180 @@
+package harvest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how Client retries throttled and failed requests.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the starting delay for exponential backoff.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff delay. Retry-After values from the server are not capped.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient. Harvest allows 100 requests per
+// 15 seconds, so a handful of retries is enough to ride out a throttled burst.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 4,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   15 * time.Second,
+}
+
+// send performs req, retrying according to the client's retry policy.
+//
+// 429 responses are always retried after the server's Retry-After, since a
+// throttled request was never processed. 5xx responses and network errors are
+// only retried for idempotent methods, except connection failures that happen
+// before anything was sent, which are safe to retry for any method.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(req)
+
+		if attempt >= c.retry.MaxRetries || !rewindable(req) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if !isIdempotent(req.Method) && !isDialError(err) {
+				return resp, err
+			}
+			delay = c.retry.backoff(attempt)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			delay = retryAfter(resp, c.retry.backoff(attempt))
+		case resp.StatusCode >= 500 && isIdempotent(req.Method):
+			delay = retryAfter(resp, c.retry.backoff(attempt))
+		default:
+			return resp, nil
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := c.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		req, err = rewind(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt performs a single HTTP round trip bounded by the client's timeout.
+// The timeout stays in effect until the response body is closed.
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	if c.timeout <= 0 {
+		return c.httpClient.Do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a per-attempt timeout once the body has been read.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// backoff returns a jittered exponential delay for the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Full jitter spreads out concurrent clients hitting the same limit.
+	return rand.N(d) + 1
+}
+
+// retryAfter parses the Retry-After header (seconds or HTTP date), falling
+// back to the given delay when it is missing or malformed.
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return fallback
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return fallback
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether err happened while establishing the connection,
+// in which case the request never reached the server.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// rewindable reports whether req's body can be replayed for another attempt.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of req with a fresh body for the next attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
@@ -0,0 +1,200 @@
+package harvest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/example/harvestcli/internal/harvest"
+	"github.com/example/harvestcli/internal/harvest/harvesttest"
+)
+
+func TestRetries(t *testing.T) {
+	me := func(ctx context.Context, c *harvest.Client) error {
+		_, err := c.Me(ctx)
+		return err
+	}
+	create := func(ctx context.Context, c *harvest.Client) error {
+		_, err := c.CreateTimeEntry(ctx, harvest.TimeEntryRequest{ProjectID: 1, TaskID: 2, SpendDate: "2026-10-16"})
+		return err
+	}
+	retryAfter := func(v string) http.Header { return http.Header{"Retry-After": {v}} }
+
+	tests := []struct {
+		name       string
+		call       func(context.Context, *harvest.Client) error
+		faults     []harvesttest.Fault
+		maxRetries int
+		wantStatus int // 0 for success
+		wantSleeps []time.Duration
+	}{
+		{
+			name:       "POST retried on 429 after Retry-After",
+			call:       create,
+			faults:     []harvesttest.Fault{{Method: "POST", Status: 429, Header: retryAfter("2")}},
+			maxRetries: 3,
+			wantSleeps: []time.Duration{2 * time.Second},
+		},
+		{
+			name:       "POST not retried on 500",
+			call:       create,
+			faults:     []harvesttest.Fault{{Method: "POST", Status: 500}},
+			maxRetries: 3,
+			wantStatus: 500,
+		},
+		{
+			name:       "GET retried on 503",
+			call:       me,
+			faults:     []harvesttest.Fault{{Status: 503, Header: retryAfter("1")}},
+			maxRetries: 3,
+			wantSleeps: []time.Duration{time.Second},
+		},
+		{
+			name: "MaxRetries respected",
+			call: me,
+			faults: []harvesttest.Fault{
+				{Status: 503, Header: retryAfter("1")},
+				{Status: 503, Header: retryAfter("1")},
+				{Status: 503, Header: retryAfter("1")},
+			},
+			maxRetries: 2,
+			wantStatus: 503,
+			wantSleeps: []time.Duration{time.Second, time.Second},
+		},
+		{
+			name:       "retries disabled",
+			call:       me,
+			faults:     []harvesttest.Fault{{Status: 429, Header: retryAfter("1")}},
+			wantStatus: 429,
+		},
+		{
+			name:       "not retried on 404",
+			call:       me,
+			faults:     []harvesttest.Fault{{Status: 404}},
+			maxRetries: 3,
+			wantStatus: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := harvesttest.NewServer()
+			defer srv.Close()
+			srv.AddProject(harvest.Project{ID: 1, Name: "Website", Active: true}, harvest.Task{ID: 2, Name: "Dev"})
+			for _, f := range tt.faults {
+				srv.InjectFault(f)
+			}
+
+			var sleeps []time.Duration
+			c := srv.Client(
+				harvest.WithRetryPolicy(harvest.RetryPolicy{MaxRetries: tt.maxRetries, BaseDelay: time.Millisecond, MaxDelay: time.Second}),
+				harvest.WithSleep(func(ctx context.Context, d time.Duration) error {
+					sleeps = append(sleeps, d)
+					return nil
+				}),
+			)
+
+			err := tt.call(context.Background(), c)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				var apiErr *harvest.APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+					t.Fatalf("got error %v, want status %d", err, tt.wantStatus)
+				}
+			}
+
+			if len(sleeps) != len(tt.wantSleeps) {
+				t.Fatalf("slept %v, want %v", sleeps, tt.wantSleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != tt.wantSleeps[i] {
+					t.Errorf("sleep %d = %v, want %v", i, sleeps[i], tt.wantSleeps[i])
+				}
+			}
+			if got, want := len(srv.Requests()), len(tt.wantSleeps)+1; got != want {
+				t.Errorf("server saw %d requests, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffWithoutRetryAfter(t *testing.T) {
+	srv := harvesttest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(harvesttest.Fault{Status: 502})
+	srv.InjectFault(harvesttest.Fault{Status: 502})
+
+	var sleeps []time.Duration
+	c := srv.Client(
+		harvest.WithRetryPolicy(harvest.RetryPolicy{MaxRetries: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: 150 * time.Millisecond}),
+		harvest.WithSleep(func(ctx context.Context, d time.Duration) error {
+			sleeps = append(sleeps, d)
+			return nil
+		}),
+	)
+	if _, err := c.Me(context.Background()); err != nil {
+		t.Fatalf("Me: %v", err)
+	}
+	// Full jitter: each delay is in (0, min(BaseDelay<<attempt, MaxDelay)].
+	caps := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond}
+	if len(sleeps) != len(caps) {
+		t.Fatalf("slept %v, want %d sleeps", sleeps, len(caps))
+	}
+	for i, d := range sleeps {
+		if d <= 0 || d > caps[i] {
+			t.Errorf("sleep %d = %v, want in (0, %v]", i, d, caps[i])
+		}
+	}
+}
+
+func TestRetryStopsWhenSleepIsInterrupted(t *testing.T) {
+	srv := harvesttest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(harvesttest.Fault{Status: 429})
+
+	c := srv.Client(
+		harvest.WithRetryPolicy(harvest.RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Second}),
+		harvest.WithSleep(func(ctx context.Context, d time.Duration) error { return context.Canceled }),
+	)
+	if _, err := c.Me(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	fallback := 3 * time.Second
+	tests := []struct {
+		name   string
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"missing", "", fallback, fallback},
+		{"seconds", "7", 7 * time.Second, 7 * time.Second},
+		{"zero seconds", "0", 0, 0},
+		{"negative", "-1", fallback, fallback},
+		{"malformed", "soon", fallback, fallback},
+		{"http date", time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+		{"http date in the past", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			got := harvest.RetryAfter(resp, fallback)
+			if got < tt.min || got > tt.max {
+				t.Errorf("RetryAfter(%q) = %v, want in [%v, %v]", tt.header, got, tt.min, tt.max)
+			}
+		})
+	}
+}
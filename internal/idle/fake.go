@@ -0,0 +1,35 @@
+package idle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fake is a Source whose idle time is set by the caller, for exercising
+// Watch and the CLI without a desktop session.
+type Fake struct {
+	mu   sync.Mutex
+	idle time.Duration
+	err  error
+}
+
+// Set changes the idle time reported from now on.
+func (f *Fake) Set(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.idle, f.err = d, nil
+}
+
+// Fail makes Idle return err until the next Set.
+func (f *Fake) Fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *Fake) Idle(ctx context.Context) (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.idle, f.err
+}
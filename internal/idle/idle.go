@@ -0,0 +1,111 @@
+// Package idle reports how long the desktop session has been idle and
+// watches for periods the user was away.
+package idle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Source reports the time since the last keyboard or mouse input.
+type Source interface {
+	Idle(ctx context.Context) (time.Duration, error)
+}
+
+// ErrNoSource is returned by Detect when no idle source is available.
+var ErrNoSource = errors.New("no idle source found: install xprintidle (X11), run GNOME, or pass a command")
+
+// Detect picks a source for the current session. GNOME's Mutter idle monitor
+// covers both Wayland and X11 under GNOME; otherwise xprintidle is used on X11.
+func Detect() (Source, error) {
+	if strings.Contains(strings.ToLower(os.Getenv("XDG_CURRENT_DESKTOP")), "gnome") {
+		if _, err := exec.LookPath("gdbus"); err == nil {
+			return Mutter{}, nil
+		}
+	}
+	if os.Getenv("DISPLAY") != "" {
+		if _, err := exec.LookPath("xprintidle"); err == nil {
+			return XPrintIdle{}, nil
+		}
+	}
+	return nil, ErrNoSource
+}
+
+// Named returns the source called name: "auto", "xprintidle", "gnome", or
+// any other string, which is run as a shell command printing idle
+// milliseconds.
+func Named(name string) (Source, error) {
+	switch name {
+	case "", "auto":
+		return Detect()
+	case "xprintidle":
+		return XPrintIdle{}, nil
+	case "gnome", "mutter":
+		return Mutter{}, nil
+	}
+	return Command{Shell: name}, nil
+}
+
+// Away is a period the user was idle for longer than the watch threshold.
+type Away struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration is the length of the away period.
+func (a Away) Duration() time.Duration {
+	return a.End.Sub(a.Start)
+}
+
+// Watch polls src every interval and sends an Away once the user returns
+// from being idle for at least threshold. The channel is closed when ctx is
+// done. Errors from src are sent to errs if it is non-nil and polling
+// continues.
+func Watch(ctx context.Context, src Source, threshold, interval time.Duration, now func() time.Time, errs chan<- error) <-chan Away {
+	out := make(chan Away, 1)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var away *Away
+		for {
+			idle, err := src.Idle(ctx)
+			t := now()
+			switch {
+			case err != nil:
+				if errs != nil && ctx.Err() == nil {
+					select {
+					case errs <- fmt.Errorf("reading idle time: %w", err):
+					default:
+					}
+				}
+			case idle >= threshold:
+				if away == nil {
+					away = &Away{Start: t.Add(-idle)}
+				}
+			case away != nil:
+				// Input arrived since the last poll, so the user is back.
+				away.End = t.Add(-idle)
+				select {
+				case out <- *away:
+				case <-ctx.Done():
+					return
+				}
+				away = nil
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,165 @@
+package idle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// step is what the Fake reports at one poll.
+type step struct {
+	idle time.Duration
+	err  error
+}
+
+// scriptSource adapts a function to Source.
+type scriptSource func(ctx context.Context) (time.Duration, error)
+
+func (f scriptSource) Idle(ctx context.Context) (time.Duration, error) { return f(ctx) }
+
+// watchScript runs Watch over steps, one per poll, with the clock advancing
+// a minute per poll from base, and returns what it sent.
+func watchScript(t *testing.T, base time.Time, threshold time.Duration, steps []step) ([]Away, []error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var src Fake
+	apply := func(s step) {
+		if s.err != nil {
+			src.Fail(s.err)
+		} else {
+			src.Set(s.idle)
+		}
+	}
+	apply(steps[0])
+	// now is called right after each poll, so it queues the next step.
+	polls := 0
+	clock := base
+	now := func() time.Time {
+		polls++
+		clock = clock.Add(time.Minute)
+		if polls < len(steps) {
+			apply(steps[polls])
+		}
+		return clock
+	}
+	// The poll after the last step only starts once Watch has sent
+	// everything for it, so that is when the test stops.
+	done := make(chan struct{})
+	script := scriptSource(func(ctx context.Context) (time.Duration, error) {
+		if polls == len(steps) {
+			close(done)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return src.Idle(ctx)
+	})
+
+	errs := make(chan error, len(steps))
+	events := Watch(ctx, script, threshold, time.Millisecond, now, errs)
+	go func() {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("Watch stopped polling")
+		}
+		cancel()
+	}()
+
+	var got []Away
+	for a := range events {
+		got = append(got, a)
+	}
+	close(errs)
+	var gotErrs []error
+	for err := range errs {
+		gotErrs = append(gotErrs, err)
+	}
+	return got, gotErrs
+}
+
+func TestWatch(t *testing.T) {
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	at := func(minutes, idle time.Duration) time.Time { return base.Add(minutes*time.Minute - idle) }
+	threshold := 10 * time.Minute
+
+	tests := []struct {
+		name  string
+		steps []step
+		want  []Away
+	}{
+		{
+			name:  "short breaks are ignored",
+			steps: []step{{idle: 0}, {idle: 5 * time.Minute}, {idle: 9 * time.Minute}, {idle: 0}},
+		},
+		{
+			name: "away from when input stopped until it resumed",
+			steps: []step{
+				{idle: 0},
+				{idle: 5 * time.Minute},
+				{idle: 12 * time.Minute},
+				{idle: 13 * time.Minute},
+				{idle: 30 * time.Second},
+				{idle: 0},
+			},
+			want: []Away{{Start: at(3, 12*time.Minute), End: at(5, 30*time.Second)}},
+		},
+		{
+			name:  "exactly the threshold counts",
+			steps: []step{{idle: threshold}, {idle: 0}},
+			want:  []Away{{Start: at(1, threshold), End: at(2, 0)}},
+		},
+		{
+			name: "each absence is reported",
+			steps: []step{
+				{idle: 20 * time.Minute},
+				{idle: time.Minute},
+				{idle: 11 * time.Minute},
+				{idle: 0},
+			},
+			want: []Away{
+				{Start: at(1, 20*time.Minute), End: at(2, time.Minute)},
+				{Start: at(3, 11*time.Minute), End: at(4, 0)},
+			},
+		},
+		{
+			name:  "still away when watching stops",
+			steps: []step{{idle: 0}, {idle: 15 * time.Minute}, {idle: 16 * time.Minute}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := watchScript(t, base, threshold, tt.steps)
+			if len(errs) != 0 {
+				t.Errorf("unexpected errors: %v", errs)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("away %d = %v-%v, want %v-%v", i, got[i].Start, got[i].End, tt.want[i].Start, tt.want[i].End)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchKeepsPollingAfterErrors(t *testing.T) {
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	broken := errors.New("no display")
+	got, errs := watchScript(t, base, 10*time.Minute, []step{
+		{idle: 15 * time.Minute},
+		{err: broken},
+		{idle: 0},
+	})
+	if len(errs) != 1 || !errors.Is(errs[0], broken) {
+		t.Errorf("errors = %v, want one wrapping %v", errs, broken)
+	}
+	want := Away{Start: base.Add(time.Minute - 15*time.Minute), End: base.Add(3 * time.Minute)}
+	if len(got) != 1 || !got[0].Start.Equal(want.Start) || !got[0].End.Equal(want.End) {
+		t.Errorf("got %v, want [%v]", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package idle
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XPrintIdle reads X11 idle time with the xprintidle tool.
+type XPrintIdle struct{}
+
+func (XPrintIdle) Idle(ctx context.Context) (time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "xprintidle").Output()
+	if err != nil {
+		return 0, fmt.Errorf("xprintidle: %w", err)
+	}
+	return parseMillis(string(out))
+}
+
+// Mutter reads idle time from GNOME's IdleMonitor over D-Bus, which works
+// under both Wayland and X11.
+type Mutter struct{}
+
+var mutterReply = regexp.MustCompile(`uint64 (\d+)`)
+
+func (Mutter) Idle(ctx context.Context) (time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "gdbus", "call", "--session",
+		"--dest", "org.gnome.Mutter.IdleMonitor",
+		"--object-path", "/org/gnome/Mutter/IdleMonitor/Core",
+		"--method", "org.gnome.Mutter.IdleMonitor.GetIdletime").Output()
+	if err != nil {
+		return 0, fmt.Errorf("gdbus: %w", err)
+	}
+	m := mutterReply.FindStringSubmatch(string(out))
+	if m == nil {
+		return 0, fmt.Errorf("gdbus: unexpected reply %q", strings.TrimSpace(string(out)))
+	}
+	return parseMillis(m[1])
+}
+
+// Command runs Shell with sh -c and reads idle milliseconds from its output,
+// for compositors with their own idle tools.
+type Command struct {
+	Shell string
+}
+
+func (c Command) Idle(ctx context.Context) (time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", c.Shell).Output()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", c.Shell, err)
+	}
+	return parseMillis(string(out))
+}
+
+func parseMillis(s string) (time.Duration, error) {
+	ms, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid idle time %q", strings.TrimSpace(s))
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
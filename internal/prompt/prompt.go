@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -236,3 +237,166 @@ func InputPrompt(message string, defaultText string) (string, error) {
 
 	return m.textInput.Value(), nil
 }
+
+// ---------- TEXTAREA MODEL ----------
+type textAreaModel struct {
+	textArea textarea.Model
+	message  string
+	quit     bool
+}
+
+func (m *textAreaModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (m *textAreaModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlS, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyCtrlC:
+			m.quit = true
+			return m, tea.Quit
+		}
+	}
+
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
+func (m *textAreaModel) View() string {
+	return fmt.Sprintf("%s\n%s\n\033[90m(ctrl+s or esc to save)\033[0m", m.message, m.textArea.View())
+}
+
+// TextAreaPrompt asks the user for multi-line text such as time entry notes.
+func TextAreaPrompt(message string, defaultText string) (string, error) {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.SetWidth(72)
+	ta.SetHeight(5)
+	ta.Focus()
+	ta.SetValue(defaultText)
+
+	m := textAreaModel{
+		textArea: ta,
+		message:  message,
+		quit:     false,
+	}
+
+	p := tea.NewProgram(&m)
+	if _, err := p.Run(); err != nil {
+		return "", err
+	}
+
+	if m.quit {
+		os.Exit(0)
+	}
+
+	return m.textArea.Value(), nil
+}
+
+// ---------- MULTI-SELECT MODEL ----------
+type multiSelectModel struct {
+	cursor   int
+	quit     bool
+	options  []string
+	selected map[int]bool
+	message  string
+}
+
+func (m *multiSelectModel) Init() tea.Cmd { return nil }
+
+func (m *multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j", "ctrl+j":
+			if m.cursor < len(m.options)-1 {
+				m.cursor++
+			}
+		case " ", "x":
+			m.selected[m.cursor] = !m.selected[m.cursor]
+		case "a":
+			all := len(m.Selected()) < len(m.options)
+			for i := range m.options {
+				m.selected[i] = all
+			}
+		case "enter":
+			return m, tea.Quit
+		case "ctrl+c", "esc", "q":
+			m.quit = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// Selected returns the chosen option indexes in order.
+func (m *multiSelectModel) Selected() []int {
+	var out []int
+	for i := range m.options {
+		if m.selected[i] {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (m *multiSelectModel) View() string {
+	s := fmt.Sprintf("%s\n", m.message)
+	s += "space to toggle, a for all, enter to confirm\n"
+	for i, o := range m.options {
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "➜ "
+		}
+		box := "[ ]"
+		if m.selected[i] {
+			box = "[x]"
+		}
+		s += fmt.Sprintf("%s %s %s\n", prefix, box, o)
+	}
+	return s
+}
+
+// MultiSelectPrompt lets the user tick any number of options and returns
+// their indexes. An empty result means nothing was picked.
+func MultiSelectPrompt(options []string, message string) ([]int, error) {
+	m := multiSelectModel{
+		options:  options,
+		selected: make(map[int]bool),
+		message:  message,
+	}
+	p := tea.NewProgram(&m)
+	if _, err := p.Run(); err != nil {
+		return nil, err
+	}
+
+	if m.quit {
+		os.Exit(0)
+	}
+
+	return m.Selected(), nil
+}
+
+// ---------- CONFIRM ----------
+
+// ConfirmPrompt asks a yes/no question, defaulting to no.
+func ConfirmPrompt(message string) (bool, error) {
+	answer, err := InputPrompt(message+" [y/N]", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
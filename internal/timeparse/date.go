@@ -0,0 +1,82 @@
+// Package timeparse parses the dates and durations accepted on the command
+// line.
+package timeparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the format Harvest uses for spent_date.
+const DateLayout = "2006-01-02"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseDate parses s relative to now. It accepts YYYY-MM-DD, "today",
+// "yesterday", "tomorrow", a weekday name ("mon", "friday") meaning the most
+// recent such day up to and including today, and day offsets such as "-2d"
+// or "+1d". The result is midnight in now's location.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	today := Day(now)
+	v := strings.ToLower(strings.TrimSpace(s))
+
+	switch v {
+	case "", "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if wd, ok := weekdays[v]; ok {
+		back := (int(today.Weekday()) - int(wd) + 7) % 7
+		return today.AddDate(0, 0, -back), nil
+	}
+
+	if (v[0] == '-' || v[0] == '+') && strings.HasSuffix(v, "d") {
+		n, err := strconv.Atoi(v[1 : len(v)-1])
+		if err == nil {
+			if v[0] == '-' {
+				n = -n
+			}
+			return today.AddDate(0, 0, n), nil
+		}
+	}
+
+	t, err := time.ParseInLocation(DateLayout, v, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, today, yesterday, a weekday or an offset like -2d", s)
+	}
+	return t, nil
+}
+
+// Day truncates t to midnight in its location.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Weekday looks up a weekday by full or abbreviated name, in any case.
+func Weekday(name string) (time.Weekday, bool) {
+	wd, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+	return wd, ok
+}
+
+// WeekStart returns midnight on the first day of the week containing t, for
+// weeks that begin on first.
+func WeekStart(t time.Time, first time.Weekday) time.Time {
+	day := Day(t)
+	back := (int(day.Weekday()) - int(first) + 7) % 7
+	return day.AddDate(0, 0, -back)
+}
@@ -0,0 +1,53 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2026, 10, 14, 15, 4, 0, 0, zone) // a Wednesday
+	tests := []struct {
+		in   string
+		want string // empty for an error
+	}{
+		{"", "2026-10-14"},
+		{"today", "2026-10-14"},
+		{" Yesterday ", "2026-10-13"},
+		{"tomorrow", "2026-10-15"},
+		{"wed", "2026-10-14"},
+		{"wednesday", "2026-10-14"},
+		{"mon", "2026-10-12"},
+		{"Thu", "2026-10-08"},
+		{"friday", "2026-10-09"},
+		{"sun", "2026-10-11"},
+		{"-2d", "2026-10-12"},
+		{"+1d", "2026-10-15"},
+		{"-14d", "2026-09-30"},
+		{"2026-01-02", "2026-01-02"},
+		{"someday", ""},
+		{"-xd", ""},
+		{"2026-13-01", ""},
+		{"14/10/2026", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, now)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", tt.in, err)
+			continue
+		}
+		if got.Format(DateLayout) != tt.want {
+			t.Errorf("ParseDate(%q) = %s, want %s", tt.in, got.Format(DateLayout), tt.want)
+		}
+		if got.Location() != zone || got.Hour() != 0 || got.Minute() != 0 {
+			t.Errorf("ParseDate(%q) = %v, want midnight in %v", tt.in, got, zone)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package timeparse
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Span is an amount of time given either as a duration or as a clock range
+// within one day.
+type Span struct {
+	// Hours is the length of the span in decimal hours.
+	Hours float64
+	// Start and End are offsets from midnight. They are only set when
+	// IsRange is true.
+	Start, End time.Duration
+	IsRange    bool
+}
+
+// ParseSpan parses a duration such as "1h30m", "90m", "1.5" or "1:30", or a
+// clock range such as "09:00-10:30" or "9am-1:15pm".
+func ParseSpan(s string) (Span, error) {
+	v := strings.TrimSpace(s)
+	if i := strings.Index(v, "-"); i > 0 {
+		start, err := ParseClock(v[:i])
+		if err != nil {
+			return Span{}, err
+		}
+		end, err := ParseClock(v[i+1:])
+		if err != nil {
+			return Span{}, err
+		}
+		if end <= start {
+			return Span{}, fmt.Errorf("invalid range %q: end must be after start", s)
+		}
+		return Span{Hours: (end - start).Hours(), Start: start, End: end, IsRange: true}, nil
+	}
+
+	hours, err := ParseHours(v)
+	if err != nil {
+		return Span{}, err
+	}
+	if hours <= 0 {
+		return Span{}, fmt.Errorf("invalid duration %q: must be greater than zero", s)
+	}
+	return Span{Hours: hours}, nil
+}
+
+// ParseHours parses a duration in decimal hours ("1.5"), hours and minutes
+// ("1:30") or Go duration syntax ("1h30m", "90m"), returning decimal hours.
+// A leading sign is allowed, and durations longer than a day are refused.
+func ParseHours(s string) (float64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if v == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	hours, ok := parseHours(v)
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q: use 1h30m, 90m, 1.5 or 1:30", s)
+	}
+	if math.Abs(hours) > 24 {
+		return 0, fmt.Errorf("invalid duration %q: more than 24 hours (minutes need a unit, as in 90m)", s)
+	}
+	return hours, nil
+}
+
+func parseHours(v string) (float64, bool) {
+	// ParseFloat also takes "inf", "nan" and exponents, so only hand it
+	// plain decimals.
+	if strings.Trim(v, "+-.0123456789") == "" {
+		h, err := strconv.ParseFloat(v, 64)
+		return h, err == nil
+	}
+
+	if h, m, ok := strings.Cut(v, ":"); ok {
+		sign := 1.0
+		if strings.HasPrefix(h, "-") {
+			sign, h = -1, h[1:]
+		}
+		hours, err1 := strconv.Atoi(h)
+		minutes, err2 := strconv.Atoi(m)
+		if err1 == nil && err2 == nil && hours >= 0 && minutes >= 0 && minutes < 60 && len(m) == 2 {
+			return sign * (float64(hours) + float64(minutes)/60), true
+		}
+	} else if d, err := time.ParseDuration(v); err == nil {
+		return d.Hours(), true
+	}
+	return 0, false
+}
+
+// ParseClock parses a time of day such as "09:00", "9:30", "9am" or
+// "1:15pm", returning the offset from midnight.
+func ParseClock(s string) (time.Duration, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	for _, layout := range []string{"15:04", "3:04pm", "3pm", "15"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time %q: use HH:MM or 9:30am", s)
+}
+
+// FormatClock formats an offset from midnight the way Harvest expects for
+// the account's clock setting, "12h" ("9:30am") or "24h" ("09:30").
+func FormatClock(d time.Duration, clock string) string {
+	t := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Add(d)
+	if clock == "12h" {
+		return t.Format("3:04pm")
+	}
+	return t.Format("15:04")
+}
@@ -0,0 +1,111 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"1.5", 1.5, false},
+		{"-0.25", -0.25, false},
+		{"1:30", 1.5, false},
+		{"0:45", 0.75, false},
+		{"-1:30", -1.5, false},
+		{"1h30m", 1.5, false},
+		{"-1h15m", -1.25, false},
+		{"90m", 1.5, false},
+		{" 2H ", 2, false},
+		{"1:5", 0, true},
+		{"1:60", 0, true},
+		{"1:-5", 0, true},
+		{"", 0, true},
+		{"soon", 0, true},
+		{"24h", 24, false},
+		{"-24", -24, false},
+		{"25h", 0, true},
+		{"90", 0, true},
+		{"-1500m", 0, true},
+		{"inf", 0, true},
+		{"-Inf", 0, true},
+		{"nan", 0, true},
+		{"1e3", 0, true},
+		{"1e-1", 0, true},
+		{"0x10", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHours(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseHours(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseHours(%q): %v", tt.in, err)
+		} else if got != tt.want {
+			t.Errorf("ParseHours(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSpan(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Span
+		wantErr bool
+	}{
+		{in: "1h30m", want: Span{Hours: 1.5}},
+		{in: "0:45", want: Span{Hours: 0.75}},
+		{in: "09:00-10:30", want: Span{Hours: 1.5, Start: 9 * time.Hour, End: 10*time.Hour + 30*time.Minute, IsRange: true}},
+		{in: "9am-1:15pm", want: Span{Hours: 4.25, Start: 9 * time.Hour, End: 13*time.Hour + 15*time.Minute, IsRange: true}},
+		{in: " 13 - 14 ", want: Span{Hours: 1, Start: 13 * time.Hour, End: 14 * time.Hour, IsRange: true}},
+		{in: "10:30-09:00", wantErr: true},
+		{in: "10:00-10:00", wantErr: true},
+		{in: "9am-25:00", wantErr: true},
+		{in: "0", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "90", wantErr: true},
+		{in: "nan", wantErr: true},
+		{in: "+inf", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpan(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSpan(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSpan(%q): %v", tt.in, err)
+		} else if got != tt.want {
+			t.Errorf("ParseSpan(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	tests := []struct {
+		d     time.Duration
+		clock string
+		want  string
+	}{
+		{9*time.Hour + 30*time.Minute, "12h", "9:30am"},
+		{13*time.Hour + 15*time.Minute, "12h", "1:15pm"},
+		{0, "12h", "12:00am"},
+		{12 * time.Hour, "12h", "12:00pm"},
+		{9*time.Hour + 30*time.Minute, "24h", "09:30"},
+		{13*time.Hour + 15*time.Minute, "24h", "13:15"},
+		{0, "24h", "00:00"},
+	}
+	for _, tt := range tests {
+		if got := FormatClock(tt.d, tt.clock); got != tt.want {
+			t.Errorf("FormatClock(%v, %q) = %q, want %q", tt.d, tt.clock, got, tt.want)
+		}
+	}
+}